@@ -5,7 +5,6 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"net"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -26,17 +25,9 @@ func main() {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
-	addr := net.JoinHostPort(cfg.PostgresCfg.Host, cfg.PostgresCfg.Port)
-	dsn := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable",
-		cfg.PostgresCfg.User,
-		cfg.PostgresCfg.Password,
-		addr,
-		cfg.PostgresCfg.DBName,
-	)
-
 	m, err := migrate.New(
 		fmt.Sprintf("file://%s", migrationsPath),
-		dsn,
+		cfg.PostgresCfg.DSN(),
 	)
 
 	if err != nil {
@@ -2,19 +2,38 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"sync"
 	"syscall"
 	"time"
 
+	"buf.build/go/protovalidate"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/admin"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/auth"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/config"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/health"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/metrics"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/patterns"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/repository"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/service"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tenant"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tlsconfig"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tracing"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/repository/cache"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/repository/database"
@@ -25,23 +44,49 @@ import (
 type App struct {
 	GRPCServer    *grpc.Server
 	GatewayServer *http.Server
+	GatewayCfg    transport.GatewayCfg
+	AdminServer   *admin.Server
 	DB            *database.OrdersDB
 	Redis         *cache.OrdersCache
+	Metrics       *metrics.Metrics
+	StopTracing   func(context.Context) error
+	Health        *health.Health
 	WG            sync.WaitGroup
 }
 
 func main() {
 	ctx := context.Background()
 
-	cfg, err := config.New()
+	flags, err := config.ParseFlags(os.Args[0], os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(flags)
+	if flags.PrintConfig {
+		if printErr := cfg.Print(os.Stdout); printErr != nil {
+			fmt.Fprintln(os.Stderr, printErr)
+		}
+	}
 	if err != nil {
-		zap.L().Error("error with config, using default settings", zap.Error(err))
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flags.PrintConfig {
+		return
 	}
 
-	ctx, err = logger.New(ctx, cfg.Environment)
+	logCtx, err := logger.NewWithOutput(ctx, cfg.Environment, cfg.OutputCfg)
 	if err != nil {
-		zap.L().Error("error with creating logger", zap.Error(err))
+		logCtx, _ = logger.New(ctx, cfg.Environment)
+		logger.GetLoggerFromCtx(logCtx).Error(logCtx, "error with log output config, logging to stderr", zap.Error(err))
 	}
+	ctx = logCtx
+	ctx = logger.WithLogger(ctx, logger.GetLoggerFromCtx(ctx).WithPayloadPolicy(logger.NewPayloadPolicy(cfg.PayloadCfg)))
 
 	app := &App{WG: sync.WaitGroup{}}
 	app.initializeComponents(ctx, cfg)
@@ -52,43 +97,248 @@ func main() {
 func (a *App) initializeComponents(ctx context.Context, cfg *config.Config) {
 	log := logger.GetLoggerFromCtx(ctx)
 
+	a.Metrics = metrics.New()
+	a.Health = health.New(cfg.HealthCfg)
+
 	var err error
+	a.StopTracing, err = tracing.Init(ctx, cfg.TracingCfg)
+	if err != nil {
+		log.Fatal(ctx, "tracing error", zap.Error(err))
+	}
+
 	a.DB, err = database.NewOrderDB(ctx, cfg.PostgresCfg)
 	if err != nil {
 		log.Fatal(ctx, "database error", zap.Error(err))
 	}
 	log.Info(ctx, "successfully connected to database")
+	if bypass, err := a.DB.BypassesRowLevelSecurity(ctx); err != nil {
+		log.Warn(ctx, "failed to check database role", zap.Error(err))
+	} else if bypass {
+		log.Warn(ctx, "database role bypasses row-level security, tenant isolation relies on query filters only",
+			zap.String("user", cfg.PostgresCfg.User),
+		)
+	}
+	a.Health.AddCheck("postgres", a.DB.Ping)
 
-	a.Redis, err = cache.NewOrdersCache(ctx, cfg.RedisCfg)
+	if err = a.Metrics.Register(metrics.NewPoolCollector(a.DB.Stat)); err != nil {
+		log.Fatal(ctx, "metrics error", zap.Error(err))
+	}
+
+	a.Redis, err = cache.NewOrdersCache(ctx, cfg.RedisCfg, a.Metrics)
 	if err != nil {
 		log.Fatal(ctx, "redis error", zap.Error(err))
 	}
 	log.Info(ctx, "succesfully connected to redis")
+	a.Health.AddCheck("redis", a.Redis.Ping)
 
-	orderRepository := repository.NewOrderRepository(a.DB, a.Redis)
+	orderRepository := repository.NewOrderRepository(a.DB, a.Redis, cfg.BulkheadCfg, a.Metrics)
 	orderService := service.NewOrderService(orderRepository)
 
 	const defaultOrdersLimit = uint64(500)
-	go orderRepository.WarmUpCache(ctx, defaultOrdersLimit)
+	a.Health.AddFlag("cache_warmup")
+	go func() {
+		orderRepository.WarmUpCache(ctx, defaultOrdersLimit)
+		a.Health.MarkReady("cache_warmup")
+	}()
+	go a.Health.Run(ctx)
 
 	srv := transport.NewOrderServer(orderService)
-	a.GRPCServer = grpc.NewServer(grpc.UnaryInterceptor(logger.LoggerInterceptor(ctx)))
+	serverCreds, dialCreds, gatewayTLS := a.tlsCredentials(ctx, cfg.TLSCfg, cfg.GatewayCfg.SinglePort)
+	unaryInterceptors, streamInterceptors := a.interceptors(ctx, cfg)
+	serverOpts := append(transport.ServerOptions(cfg.GRPCServerCfg),
+		grpc.Creds(serverCreds),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
+	a.GRPCServer = grpc.NewServer(serverOpts...)
 	api.RegisterOrderServiceServer(a.GRPCServer, srv)
+	healthpb.RegisterHealthServer(a.GRPCServer, a.Health.Server())
 
-	go func() {
-		log.Info(ctx, "starting gRPC server...", zap.String("port", cfg.GrpcPort))
-		if err = srv.Start(ctx, a.GRPCServer, cfg.GrpcPort); err != nil {
-			log.Fatal(ctx, "failed to start gRPC server", zap.Error(err))
+	if !cfg.GatewayCfg.SinglePort {
+		go func() {
+			log.Info(ctx, "starting gRPC server...", zap.String("port", cfg.GrpcPort))
+			if err := srv.Start(ctx, a.GRPCServer, cfg.GrpcPort); err != nil {
+				log.Fatal(ctx, "failed to start gRPC server", zap.Error(err))
+			}
+		}()
+	}
+
+	routes := map[string]http.Handler{
+		"/healthz": a.Health.LivenessHandler(),
+		"/readyz":  a.Health.ReadinessHandler(),
+	}
+	// operational endpoints stay off the public port when the admin listener
+	// is available
+	internalRoutes := map[string]http.Handler{
+		"/metrics": a.Metrics.Handler(),
+	}
+	if levels := log.Levels(); cfg.LevelCfg.EndpointEnabled && levels != nil {
+		internalRoutes["/admin/log-level"] = levels.Handler()
+	}
+
+	if cfg.AdminCfg.Enabled {
+		a.AdminServer = admin.New(cfg.AdminCfg, a.GRPCServer, internalRoutes)
+		if err = a.AdminServer.Start(ctx); err != nil {
+			log.Fatal(ctx, "failed to start admin server", zap.Error(err))
 		}
-	}()
+	} else {
+		maps.Copy(routes, internalRoutes)
+	}
 
-	log.Info(ctx, "starting gRPC gateway...", zap.String("port", cfg.GatewayPort))
-	a.GatewayServer, err = transport.StartGateway(ctx, cfg.GrpcPort, cfg.GatewayPort)
+	log.Info(ctx, "starting gRPC gateway...",
+		zap.String("port", cfg.GatewayPort),
+		zap.String("mode", cfg.GatewayCfg.Mode),
+		zap.Bool("single_port", cfg.GatewayCfg.SinglePort),
+	)
+	a.GatewayCfg = cfg.GatewayCfg
+	a.GatewayServer, err = transport.StartGateway(ctx, cfg.GatewayCfg, transport.GatewayParams{
+		GRPCServer:     a.GRPCServer,
+		GRPCPort:       cfg.GrpcPort,
+		GatewayPort:    cfg.GatewayPort,
+		Routes:         routes,
+		DialCreds:      dialCreds,
+		TLS:            gatewayTLS,
+		MaxRecvMsgSize: cfg.MaxSendMsgSize,
+	})
 	if err != nil {
 		log.Fatal(ctx, "failed to start gRPC gateway", zap.Error(err))
 	}
 }
 
+func (a *App) tlsCredentials(
+	ctx context.Context,
+	cfg tlsconfig.TLSCfg,
+	singlePort bool,
+) (credentials.TransportCredentials, credentials.TransportCredentials, *tls.Config) {
+	log := logger.GetLoggerFromCtx(ctx)
+
+	serverCreds, dialCreds := insecure.NewCredentials(), insecure.NewCredentials()
+	if cfg.Enabled {
+		serverTLS, err := tlsconfig.NewServerConfig(ctx, cfg)
+		if err != nil {
+			log.Fatal(ctx, "grpc tls error", zap.Error(err))
+		}
+		clientTLS, err := tlsconfig.NewClientConfig(ctx, cfg)
+		if err != nil {
+			log.Fatal(ctx, "gateway tls error", zap.Error(err))
+		}
+		serverCreds, dialCreds = credentials.NewTLS(serverTLS), credentials.NewTLS(clientTLS)
+	}
+
+	if !cfg.GatewayHTTPS {
+		return serverCreds, dialCreds, nil
+	}
+
+	// browsers and probes don't carry client certificates, so the gateway
+	// only terminates TLS and leaves client verification to the gRPC listener;
+	// in single-port mode it is the gRPC listener too, so certificates are
+	// verified when given and mTLS callers still authenticate
+	gatewayCfg := cfg
+	gatewayCfg.RequireClientCert = false
+	if !singlePort {
+		gatewayCfg.ClientCAFile = ""
+	}
+	gatewayTLS, err := tlsconfig.NewServerConfig(ctx, gatewayCfg)
+	if err != nil {
+		log.Fatal(ctx, "gateway https error", zap.Error(err))
+	}
+
+	return serverCreds, dialCreds, gatewayTLS
+}
+
+func (a *App) interceptors(
+	ctx context.Context,
+	cfg *config.Config,
+) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
+	log := logger.GetLoggerFromCtx(ctx)
+
+	available := map[string][]transport.Interceptor{
+		transport.InterceptorRecovery: {{
+			Unary:  transport.RecoveryInterceptor(ctx),
+			Stream: transport.RecoveryStreamInterceptor(ctx),
+		}},
+		transport.InterceptorRequestID: {{
+			Unary:  logger.RequestIDInterceptor(),
+			Stream: logger.RequestIDStreamInterceptor(),
+		}},
+		transport.InterceptorLogging: {{
+			Unary:  logger.LoggerInterceptor(ctx),
+			Stream: logger.LoggerStreamInterceptor(ctx),
+		}},
+		transport.InterceptorMetrics: {{
+			Unary:  metrics.MetricsInterceptor(a.Metrics),
+			Stream: metrics.MetricsStreamInterceptor(a.Metrics),
+		}},
+		transport.InterceptorTenant: {{
+			Unary:  tenant.TenantInterceptor(cfg.TenantCfg),
+			Stream: tenant.TenantStreamInterceptor(cfg.TenantCfg),
+		}},
+	}
+
+	validator, err := protovalidate.New()
+	if err != nil {
+		log.Fatal(ctx, "validator error", zap.Error(err))
+	}
+	available[transport.InterceptorValidation] = []transport.Interceptor{{
+		Unary:  transport.ValidationInterceptor(validator),
+		Stream: transport.ValidationStreamInterceptor(validator),
+	}}
+
+	if cfg.AuthCfg.Enabled {
+		authenticator, err := auth.NewAuthenticator(cfg.AuthCfg)
+		if err != nil {
+			log.Fatal(ctx, "auth error", zap.Error(err))
+		}
+
+		policy := auth.DefaultPolicy()
+		if cfg.PolicyFile != "" {
+			if policy, err = auth.LoadPolicy(cfg.PolicyFile); err != nil {
+				log.Fatal(ctx, "auth policy error", zap.Error(err))
+			}
+		}
+
+		publicMethods := append(slices.Clone(cfg.PublicMethods), health.PublicMethods()...)
+		available[transport.InterceptorAuth] = []transport.Interceptor{
+			{
+				Unary:  auth.AuthInterceptor(authenticator, publicMethods),
+				Stream: auth.AuthStreamInterceptor(authenticator, publicMethods),
+			},
+			{
+				Unary:  auth.AuthzInterceptor(policy, publicMethods),
+				Stream: auth.AuthzStreamInterceptor(policy, publicMethods),
+			},
+		}
+	}
+
+	if cfg.RateLimitCfg.Enabled {
+		limiter := a.newRateLimiter(cfg.RateLimitCfg)
+		trustedProxies, err := transport.ParseTrustedProxies(cfg.TrustedProxies)
+		if err != nil {
+			log.Fatal(ctx, "rate limit config error", zap.Error(err))
+		}
+		available[transport.InterceptorRateLimit] = []transport.Interceptor{{
+			Unary:  transport.RateLimitInterceptor(limiter, cfg.KeyBy, trustedProxies),
+			Stream: transport.RateLimitStreamInterceptor(limiter, cfg.KeyBy, trustedProxies),
+		}}
+	}
+
+	unary, stream, err := transport.Chain(cfg.InterceptorsCfg.Interceptors, available)
+	if err != nil {
+		log.Fatal(ctx, "interceptors config error", zap.Error(err))
+	}
+
+	return unary, stream
+}
+
+func (a *App) newRateLimiter(cfg transport.RateLimitCfg) transport.RateLimiter {
+	if cfg.Backend == transport.RateLimitBackendRedis {
+		return cache.NewRedisRateLimiter(a.Redis, cfg.Rate, cfg.Burst)
+	}
+
+	return patterns.NewTokenBucketLimiter(cfg.Rate, cfg.Burst)
+}
+
 func (a *App) gracefulShotdown(ctx context.Context) {
 	log := logger.GetLoggerFromCtx(ctx)
 
@@ -102,15 +352,21 @@ func (a *App) gracefulShotdown(ctx context.Context) {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), defaultShutdownTTL)
 	defer cancel()
 
-	log.Info(ctx, "shutting down gRPC server...")
-	a.GRPCServer.GracefulStop()
-	log.Info(ctx, "gRPC server stopped successfully")
+	log.Info(ctx, "marking service as not ready...")
+	a.Health.Shutdown()
 
-	log.Info(ctx, "shutting down gRPC gateway...")
-	if err := a.GatewayServer.Shutdown(shutdownCtx); err != nil {
+	log.Info(ctx, "shutting down gRPC server and gateway...")
+	if err := transport.Shutdown(shutdownCtx, a.GatewayCfg, a.GRPCServer, a.GatewayServer); err != nil {
 		log.Error(ctx, "failed to shutdown gRPC gateway", zap.Error(err))
 	} else {
-		log.Info(ctx, "gRPC gateway stopped successfully")
+		log.Info(ctx, "gRPC server and gateway stopped successfully")
+	}
+
+	if a.AdminServer != nil {
+		log.Info(ctx, "shutting down admin server...")
+		if err := a.AdminServer.Shutdown(shutdownCtx); err != nil {
+			log.Error(ctx, "failed to shutdown admin server", zap.Error(err))
+		}
 	}
 
 	log.Info(ctx, "waiting for background operations...")
@@ -148,4 +404,13 @@ func (a *App) closeConnections(ctx context.Context) {
 		a.Redis.Close(ctx)
 		zap.L().Info("Redis connection closed")
 	}
+
+	if a.StopTracing != nil {
+		zap.L().Info("flushing traces...")
+		if err := a.StopTracing(ctx); err != nil {
+			zap.L().Error("failed to flush traces", zap.Error(err))
+		}
+	}
+
+	_ = logger.GetLoggerFromCtx(ctx).Sync()
 }
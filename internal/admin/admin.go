@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	channelzsvc "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+type AdminCfg struct {
+	Enabled    bool   `env:"ADMIN_ENABLED"         env-default:"true"`
+	Host       string `env:"ADMIN_HOST"            env-default:"127.0.0.1"`
+	Port       string `env:"ADMIN_PORT"            env-default:"9090"`
+	Reflection bool   `env:"ADMIN_GRPC_REFLECTION" env-default:"false"`
+	Channelz   bool   `env:"ADMIN_GRPC_CHANNELZ"   env-default:"false"`
+	Pprof      bool   `env:"ADMIN_PPROF"           env-default:"true"`
+}
+
+// Server is the internal admin listener. It speaks plaintext HTTP/1.1 and
+// h2c on a single port: gRPC requests go to the reflection and channelz
+// services, everything else to pprof and the extra routes.
+type Server struct {
+	cfg  AdminCfg
+	grpc *grpc.Server
+	http *http.Server
+}
+
+// New builds the admin server. Reflection describes the services registered
+// on public rather than on the admin server itself, so grpcurl pointed at the
+// admin port can list and describe the public API.
+func New(cfg AdminCfg, public reflection.ServiceInfoProvider, routes map[string]http.Handler) *Server {
+	grpcServer := grpc.NewServer()
+	if cfg.Reflection {
+		opts := reflection.ServerOptions{Services: public}
+		reflectionv1.RegisterServerReflectionServer(grpcServer, reflection.NewServerV1(opts))
+		reflectionv1alpha.RegisterServerReflectionServer(grpcServer, reflection.NewServer(opts))
+	}
+	if cfg.Channelz {
+		channelzsvc.RegisterChannelzServiceToServer(grpcServer)
+	}
+
+	mux := http.NewServeMux()
+	if cfg.Pprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	for pattern, handler := range routes {
+		mux.Handle(pattern, handler)
+	}
+
+	const defaultAdminTimeout = 5 * time.Second
+	return &Server{
+		cfg:  cfg,
+		grpc: grpcServer,
+		http: &http.Server{
+			Addr:              net.JoinHostPort(cfg.Host, cfg.Port),
+			Handler:           h2c.NewHandler(transport.GRPCOrHTTP(grpcServer, mux), &http2.Server{}),
+			ReadHeaderTimeout: defaultAdminTimeout,
+		},
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	return s.http.Handler
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen admin address: %w", err)
+	}
+
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "starting admin server...",
+		zap.String("addr", lis.Addr().String()),
+		zap.Bool("reflection", s.cfg.Reflection),
+		zap.Bool("channelz", s.cfg.Channelz),
+		zap.Bool("pprof", s.cfg.Pprof),
+	)
+
+	go func() {
+		if err := s.http.Serve(lis); err != nil && err != http.ErrServerClosed {
+			logger.GetLoggerFromCtx(ctx).Error(ctx, "admin server stopped", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.grpc.Stop()
+
+	return s.http.Shutdown(ctx)
+}
@@ -0,0 +1,105 @@
+package admin_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/admin"
+	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+)
+
+func startAdmin(t *testing.T, cfg admin.AdminCfg, routes map[string]http.Handler) (*httptest.Server, *grpc.ClientConn) {
+	t.Helper()
+
+	public := grpc.NewServer()
+	api.RegisterOrderServiceServer(public, api.UnimplementedOrderServiceServer{})
+
+	srv := httptest.NewServer(admin.New(cfg, public, routes).Handler())
+	t.Cleanup(srv.Close)
+
+	conn, err := grpc.NewClient(strings.TrimPrefix(srv.URL, "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return srv, conn
+}
+
+func listServices(ctx context.Context, conn *grpc.ClientConn) ([]string, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+
+	return services, nil
+}
+
+func TestAdmin_Enabled(t *testing.T) {
+	ctx := context.Background()
+	routes := map[string]http.Handler{
+		"/admin/log-level": http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	}
+	srv, conn := startAdmin(t, admin.AdminCfg{Reflection: true, Channelz: true, Pprof: true}, routes)
+
+	services, err := listServices(ctx, conn)
+	require.NoError(t, err)
+	assert.Equal(t, []string{api.OrderService_ServiceDesc.ServiceName}, services, "reflection describes the public server")
+
+	_, err = channelzpb.NewChannelzClient(conn).GetServers(ctx, &channelzpb.GetServersRequest{})
+	require.NoError(t, err)
+
+	resp, err := http.Get(srv.URL + "/debug/pprof/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "/admin/log-level")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+}
+
+func TestAdmin_Disabled(t *testing.T) {
+	ctx := context.Background()
+	srv, conn := startAdmin(t, admin.AdminCfg{}, nil)
+
+	_, err := listServices(ctx, conn)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = channelzpb.NewChannelzClient(conn).GetServers(ctx, &channelzpb.GetServersRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	resp, err := http.Get(srv.URL + "/debug/pprof/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,429 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/auth"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testSecret = "test-secret"
+
+func writeFile(t *testing.T, name string, v any) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func signHS256(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+
+	return token
+}
+
+func bearerCtx(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestAuthenticator_HS256(t *testing.T) {
+	a, err := auth.NewAuthenticator(auth.AuthCfg{JWTSecret: testSecret, Issuer: "orders"})
+	require.NoError(t, err)
+
+	t.Run("valid token", func(t *testing.T) {
+		token := signHS256(t, jwt.MapClaims{
+			"sub":   "user-1",
+			"iss":   "orders",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"client"},
+			"scope": "orders:read orders:write",
+		})
+
+		p, err := a.Authenticate(bearerCtx(token))
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", p.Subject)
+		assert.Equal(t, []string{"client"}, p.Roles)
+		assert.Equal(t, []string{"orders:read", "orders:write"}, p.Scopes)
+		assert.Equal(t, auth.MethodJWT, p.Method)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		token := signHS256(t, jwt.MapClaims{
+			"sub": "user-1",
+			"iss": "orders",
+			"exp": time.Now().Add(-time.Hour).Unix(),
+		})
+
+		_, err := a.Authenticate(bearerCtx(token))
+		require.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	t.Run("wrong issuer", func(t *testing.T) {
+		token := signHS256(t, jwt.MapClaims{
+			"sub": "user-1",
+			"iss": "someone-else",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+
+		_, err := a.Authenticate(bearerCtx(token))
+		require.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	t.Run("not a bearer token", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Basic Zm9vOmJhcg=="))
+
+		_, err := a.Authenticate(ctx)
+		require.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		_, err := a.Authenticate(context.Background())
+		require.ErrorIs(t, err, auth.ErrMissingCredentials)
+	})
+}
+
+func TestAuthenticator_RS256WithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksFile := writeFile(t, "jwks.json", map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+
+	a, err := auth.NewAuthenticator(auth.AuthCfg{JWKSFile: jwksFile})
+	require.NoError(t, err)
+
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"sub": "user-2",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = kid
+
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	p, err := a.Authenticate(bearerCtx(sign("key-1")))
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", p.Subject)
+
+	_, err = a.Authenticate(bearerCtx(sign("unknown")))
+	require.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestAuthenticator_APIKeys(t *testing.T) {
+	keysFile := writeFile(t, "api_keys.json", []map[string]any{
+		{"key": "secret-key", "subject": "billing-service", "roles": []string{"admin"}},
+	})
+
+	a, err := auth.NewAuthenticator(auth.AuthCfg{APIKeysFile: keysFile})
+	require.NoError(t, err)
+
+	p, err := a.Authenticate(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "secret-key")))
+	require.NoError(t, err)
+	assert.Equal(t, "billing-service", p.Subject)
+	assert.True(t, p.HasRole("admin"))
+	assert.Equal(t, auth.MethodAPIKey, p.Method)
+
+	_, err = a.Authenticate(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "wrong")))
+	require.ErrorIs(t, err, auth.ErrInvalidAPIKey)
+}
+
+func TestAuthenticator_ClientCertificate(t *testing.T) {
+	a, err := auth.NewAuthenticator(auth.AuthCfg{
+		MTLSEnabled:       true,
+		MTLSRoles:         []string{auth.RoleClient},
+		MTLSProxySubjects: []string{"order-gateway"},
+	})
+	require.NoError(t, err)
+
+	peerCtx := func(cert *x509.Certificate) context.Context {
+		state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	}
+
+	p, err := a.Authenticate(peerCtx(&x509.Certificate{
+		Subject: pkix.Name{CommonName: "warehouse", Organization: []string{"acme"}},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "warehouse", p.Subject)
+	assert.Equal(t, "acme", p.TenantID)
+	assert.Equal(t, auth.MethodMTLS, p.Method)
+	assert.True(t, p.HasRole(auth.RoleClient))
+
+	_, err = a.Authenticate(peerCtx(&x509.Certificate{Subject: pkix.Name{CommonName: "order-gateway"}}))
+	require.ErrorIs(t, err, auth.ErrMissingCredentials, "proxy certificate must not become a principal")
+
+	_, err = a.Authenticate(peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}}))
+	require.ErrorIs(t, err, auth.ErrMissingCredentials, "unverified connection")
+}
+
+func TestNewAuthenticator_NoCredentials(t *testing.T) {
+	_, err := auth.NewAuthenticator(auth.AuthCfg{})
+	require.Error(t, err)
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	a, err := auth.NewAuthenticator(auth.AuthCfg{JWTSecret: testSecret})
+	require.NoError(t, err)
+
+	ctx, err := logger.New(context.Background(), "")
+	require.NoError(t, err)
+
+	interceptor := auth.AuthInterceptor(a, []string{"/api.OrderService/ListOrders"})
+	handler := func(ctx context.Context, _ any) (any, error) {
+		p, ok := auth.PrincipalFromCtx(ctx)
+		if !ok {
+			return "anonymous", nil
+		}
+		return p.Subject, nil
+	}
+
+	t.Run("authenticated request gets principal", func(t *testing.T) {
+		token := signHS256(t, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()})
+		md := metadata.Pairs("authorization", "Bearer "+token)
+
+		resp, err := interceptor(metadata.NewIncomingContext(ctx, md), nil,
+			&grpc.UnaryServerInfo{FullMethod: "/api.OrderService/DeleteOrder"}, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", resp)
+	})
+
+	t.Run("unauthenticated request is rejected", func(t *testing.T) {
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/api.OrderService/DeleteOrder"}, handler)
+		require.Error(t, err)
+		assert.Nil(t, resp)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("public method skips authentication", func(t *testing.T) {
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/api.OrderService/ListOrders"}, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "anonymous", resp)
+	})
+}
+
+type stubServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s stubServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestAuthStreamInterceptor(t *testing.T) {
+	a, err := auth.NewAuthenticator(auth.AuthCfg{JWTSecret: testSecret})
+	require.NoError(t, err)
+
+	ctx, err := logger.New(context.Background(), "")
+	require.NoError(t, err)
+
+	interceptor := auth.AuthStreamInterceptor(a, nil)
+	info := &grpc.StreamServerInfo{FullMethod: "/api.OrderService/WatchOrders", IsServerStream: true}
+
+	t.Run("authenticated stream gets principal", func(t *testing.T) {
+		token := signHS256(t, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()})
+		ss := stubServerStream{ctx: metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))}
+
+		var subject string
+		err := interceptor(nil, ss, info, func(_ any, stream grpc.ServerStream) error {
+			p, _ := auth.PrincipalFromCtx(stream.Context())
+			subject = p.Subject
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", subject)
+	})
+
+	t.Run("unauthenticated stream is rejected", func(t *testing.T) {
+		called := false
+		err := interceptor(nil, stubServerStream{ctx: ctx}, info, func(any, grpc.ServerStream) error {
+			called = true
+			return nil
+		})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.False(t, called)
+	})
+}
+
+type stubOrderService struct{}
+
+func (stubOrderService) CreateOrder(context.Context, string, int32) (string, error) {
+	return "123", nil
+}
+
+func (stubOrderService) GetOrder(_ context.Context, id string) (*api.Order, error) {
+	return &api.Order{Id: id, Item: "laptop", Quantity: 1}, nil
+}
+
+func (stubOrderService) UpdateOrder(_ context.Context, id string, item string, quantity int32) (*api.Order, error) {
+	return &api.Order{Id: id, Item: item, Quantity: quantity}, nil
+}
+
+func (stubOrderService) DeleteOrder(context.Context, string) (bool, error) {
+	return true, nil
+}
+
+func (stubOrderService) ListOrders(context.Context) ([]*api.Order, error) {
+	return []*api.Order{}, nil
+}
+
+func startOrderServer(t *testing.T, policy *auth.Policy) api.OrderServiceClient {
+	t.Helper()
+
+	ctx, err := logger.New(context.Background(), "")
+	require.NoError(t, err)
+
+	authenticator, err := auth.NewAuthenticator(auth.AuthCfg{JWTSecret: testSecret})
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		logger.LoggerInterceptor(ctx),
+		auth.AuthInterceptor(authenticator, nil),
+		auth.AuthzInterceptor(policy, nil),
+	))
+	api.RegisterOrderServiceServer(server, transport.NewOrderServer(stubOrderService{}))
+
+	lis := bufconn.Listen(1 << 20)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return api.NewOrderServiceClient(conn)
+}
+
+func withRoles(t *testing.T, roles ...string) context.Context {
+	t.Helper()
+
+	token := signHS256(t, jwt.MapClaims{
+		"sub":   "user-1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	})
+
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestAuthzInterceptor_DefaultPolicy(t *testing.T) {
+	client := startOrderServer(t, auth.DefaultPolicy())
+
+	t.Run("client can create and read", func(t *testing.T) {
+		ctx := withRoles(t, auth.RoleClient)
+
+		_, err := client.CreateOrder(ctx, &api.CreateOrderRequest{Item: "laptop", Quantity: 1})
+		require.NoError(t, err)
+
+		_, err = client.GetOrder(ctx, &api.GetOrderRequest{Id: "123"})
+		require.NoError(t, err)
+
+		_, err = client.ListOrders(ctx, &api.ListOrdersRequest{})
+		require.NoError(t, err)
+	})
+
+	t.Run("client cannot update or delete", func(t *testing.T) {
+		ctx := withRoles(t, auth.RoleClient)
+
+		_, err := client.UpdateOrder(ctx, &api.UpdateOrderRequest{Id: "123", Item: "mouse", Quantity: 1})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		_, err = client.DeleteOrder(ctx, &api.DeleteOrderRequest{Id: "123"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("admin can delete", func(t *testing.T) {
+		resp, err := client.DeleteOrder(withRoles(t, auth.RoleAdmin), &api.DeleteOrderRequest{Id: "123"})
+		require.NoError(t, err)
+		assert.True(t, resp.GetSuccess())
+	})
+
+	t.Run("caller without roles is denied", func(t *testing.T) {
+		_, err := client.GetOrder(withRoles(t), &api.GetOrderRequest{Id: "123"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("anonymous caller is unauthenticated", func(t *testing.T) {
+		_, err := client.GetOrder(context.Background(), &api.GetOrderRequest{Id: "123"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestAuthzInterceptor_PolicyFromFile(t *testing.T) {
+	policyFile := writeFile(t, "policy.json", map[string]any{
+		"methods": map[string]any{
+			"/api.OrderService/GetOrder": map[string]any{"scopes": []string{"orders:read"}},
+		},
+		"default_deny": false,
+	})
+
+	policy, err := auth.LoadPolicy(policyFile)
+	require.NoError(t, err)
+
+	client := startOrderServer(t, policy)
+
+	_, err = client.GetOrder(withRoles(t, auth.RoleAdmin), &api.GetOrderRequest{Id: "123"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	token := signHS256(t, jwt.MapClaims{
+		"sub":   "user-1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "orders:read",
+	})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+
+	_, err = client.GetOrder(ctx, &api.GetOrderRequest{Id: "123"})
+	require.NoError(t, err)
+
+	_, err = client.DeleteOrder(ctx, &api.DeleteOrderRequest{Id: "123"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	authorizationHeader = "authorization"
+	apiKeyHeader        = "x-api-key"
+	bearerPrefix        = "bearer "
+)
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrInvalidAPIKey      = errors.New("invalid api key")
+)
+
+type AuthCfg struct {
+	Enabled       bool     `env:"AUTH_ENABLED"        env-default:"true"`
+	JWTSecret     string   `env:"AUTH_JWT_SECRET" secret:"true"`
+	JWKSFile      string   `env:"AUTH_JWKS_FILE"`
+	Issuer        string   `env:"AUTH_JWT_ISSUER"`
+	Audience      string   `env:"AUTH_JWT_AUDIENCE"`
+	APIKeysFile   string   `env:"AUTH_API_KEYS_FILE"`
+	PolicyFile    string   `env:"AUTH_POLICY_FILE"`
+	PublicMethods []string `env:"AUTH_PUBLIC_METHODS" env-separator:","`
+
+	MTLSEnabled       bool     `env:"AUTH_MTLS_ENABLED"        env-default:"false"`
+	MTLSRoles         []string `env:"AUTH_MTLS_ROLES"          env-default:"client" env-separator:","`
+	MTLSProxySubjects []string `env:"AUTH_MTLS_PROXY_SUBJECTS" env-separator:","`
+}
+
+type apiKeyEntry struct {
+	Key      string   `json:"key"`
+	Subject  string   `json:"subject"`
+	TenantID string   `json:"tenant_id"`
+	Roles    []string `json:"roles"`
+	Scopes   []string `json:"scopes"`
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+
+	TenantID string   `json:"tenant_id"`
+	Roles    []string `json:"roles"`
+	Scope    string   `json:"scope"`
+}
+
+type Authenticator struct {
+	hmacSecret []byte
+	jwks       map[string]verificationKey
+	apiKeys    map[[sha256.Size]byte]*Principal
+	parser     *jwt.Parser
+
+	mtls              bool
+	mtlsRoles         []string
+	mtlsProxySubjects []string
+}
+
+func NewAuthenticator(cfg AuthCfg) (*Authenticator, error) {
+	a := &Authenticator{
+		apiKeys:           make(map[[sha256.Size]byte]*Principal),
+		mtls:              cfg.MTLSEnabled,
+		mtlsRoles:         cfg.MTLSRoles,
+		mtlsProxySubjects: cfg.MTLSProxySubjects,
+	}
+
+	if cfg.JWTSecret != "" {
+		a.hmacSecret = []byte(cfg.JWTSecret)
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		a.jwks = keys
+	}
+
+	if cfg.APIKeysFile != "" {
+		if err := a.loadAPIKeys(cfg.APIKeysFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if a.hmacSecret == nil && a.jwks == nil && len(a.apiKeys) == 0 && !a.mtls {
+		return nil, errors.New("auth: no jwt secret, jwks file, api keys or mtls configured")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	a.parser = jwt.NewParser(opts...)
+
+	return a, nil
+}
+
+func (a *Authenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get(authorizationHeader); len(values) > 0 {
+		header := values[0]
+		if len(header) < len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+			return nil, fmt.Errorf("%w: expected bearer token", ErrInvalidToken)
+		}
+
+		return a.authenticateJWT(strings.TrimSpace(header[len(bearerPrefix):]))
+	}
+
+	if values := md.Get(apiKeyHeader); len(values) > 0 {
+		return a.authenticateAPIKey(values[0])
+	}
+
+	if a.mtls {
+		if principal, ok := a.authenticateClientCert(ctx); ok {
+			return principal, nil
+		}
+	}
+
+	return nil, ErrMissingCredentials
+}
+
+func (a *Authenticator) authenticateJWT(raw string) (*Principal, error) {
+	var c claims
+	_, err := a.parser.ParseWithClaims(raw, &c, a.keyFunc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if c.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	return &Principal{
+		Subject:  c.Subject,
+		TenantID: c.TenantID,
+		Roles:    c.Roles,
+		Scopes:   strings.Fields(c.Scope),
+		Method:   MethodJWT,
+	}, nil
+}
+
+func (a *Authenticator) keyFunc(token *jwt.Token) (any, error) {
+	alg := token.Method.Alg()
+
+	if kid, ok := token.Header["kid"].(string); ok && a.jwks != nil {
+		vk, found := a.jwks[kid]
+		if !found {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		if vk.alg != alg {
+			return nil, fmt.Errorf("key %q does not match algorithm %s", kid, alg)
+		}
+		return vk.key, nil
+	}
+
+	if alg == "HS256" && a.hmacSecret != nil {
+		return a.hmacSecret, nil
+	}
+
+	return nil, fmt.Errorf("no verification key for algorithm %s", alg)
+}
+
+func (a *Authenticator) authenticateAPIKey(raw string) (*Principal, error) {
+	p, ok := a.apiKeys[sha256.Sum256([]byte(raw))]
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	return p, nil
+}
+
+func (a *Authenticator) loadAPIKeys(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read api keys: %w", err)
+	}
+
+	var entries []apiKeyEntry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("parse api keys: %w", err)
+	}
+
+	for _, e := range entries {
+		if e.Key == "" || e.Subject == "" {
+			return errors.New("parse api keys: key and subject are required")
+		}
+
+		a.apiKeys[sha256.Sum256([]byte(e.Key))] = &Principal{
+			Subject:  e.Subject,
+			TenantID: e.TenantID,
+			Roles:    e.Roles,
+			Scopes:   e.Scopes,
+			Method:   MethodAPIKey,
+		}
+	}
+
+	return nil
+}
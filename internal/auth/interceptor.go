@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/grpcutil"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func AuthInterceptor(authenticator *Authenticator, publicMethods []string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		ctx, err := authenticate(ctx, authenticator, publicMethods, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func AuthStreamInterceptor(authenticator *Authenticator, publicMethods []string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authenticator, publicMethods, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, grpcutil.WithContext(ss, ctx))
+	}
+}
+
+func AuthzInterceptor(policy *Policy, publicMethods []string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if err := authorize(ctx, policy, publicMethods, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func AuthzStreamInterceptor(policy *Policy, publicMethods []string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), policy, publicMethods, info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func authenticate(
+	ctx context.Context,
+	authenticator *Authenticator,
+	publicMethods []string,
+	method string,
+) (context.Context, error) {
+	if slices.Contains(publicMethods, method) {
+		return ctx, nil
+	}
+
+	principal, err := authenticator.Authenticate(ctx)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Named("auth").Warn(ctx, "authentication failed",
+			zap.String("method", method),
+			zap.Error(err),
+		)
+		return ctx, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	return WithPrincipal(ctx, principal), nil
+}
+
+func authorize(ctx context.Context, policy *Policy, publicMethods []string, method string) error {
+	if slices.Contains(publicMethods, method) {
+		return nil
+	}
+
+	principal, _ := PrincipalFromCtx(ctx)
+	if err := policy.Authorize(method, principal); err != nil {
+		logger.GetLoggerFromCtx(ctx).Named("auth").Warn(ctx, "authorization failed",
+			zap.String("method", method),
+			zap.Error(err),
+		)
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	return nil
+}
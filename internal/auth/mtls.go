@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"slices"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// authenticateClientCert maps a verified client certificate to a principal:
+// the subject is the CN (or the first URI/DNS SAN) and the tenant is the
+// first O. Proxy subjects such as the gateway's own certificate are skipped,
+// otherwise every request relayed by the gateway would inherit its identity.
+func (a *Authenticator) authenticateClientCert(ctx context.Context) (*Principal, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	subject := certSubject(cert)
+	if subject == "" || slices.Contains(a.mtlsProxySubjects, subject) {
+		return nil, false
+	}
+
+	principal := &Principal{
+		Subject: subject,
+		Roles:   slices.Clone(a.mtlsRoles),
+		Method:  MethodMTLS,
+	}
+	if len(cert.Subject.Organization) > 0 {
+		principal.TenantID = cert.Subject.Organization[0]
+	}
+
+	return principal, true
+}
+
+func certSubject(cert *x509.Certificate) string {
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+const (
+	RoleAdmin  = "admin"
+	RoleClient = "client"
+	// RolePlatformAdmin is held by operators of the whole installation, unlike
+	// RoleAdmin it is not granted per tenant.
+	RolePlatformAdmin = "platform_admin"
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+type Rule struct {
+	Roles  []string `json:"roles"`
+	Scopes []string `json:"scopes"`
+}
+
+type Policy struct {
+	Methods     map[string]Rule `json:"methods"`
+	DefaultDeny bool            `json:"default_deny"`
+}
+
+func DefaultPolicy() *Policy {
+	readers := Rule{Roles: []string{RoleClient, RoleAdmin}}
+	admins := Rule{Roles: []string{RoleAdmin}}
+
+	return &Policy{
+		Methods: map[string]Rule{
+			"/api.OrderService/CreateOrder": readers,
+			"/api.OrderService/GetOrder":    readers,
+			"/api.OrderService/ListOrders":  readers,
+			"/api.OrderService/UpdateOrder": admins,
+			"/api.OrderService/DeleteOrder": admins,
+		},
+		DefaultDeny: true,
+	}
+}
+
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+
+	var p Policy
+	if err = json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse policy: %w", err)
+	}
+
+	if p.Methods == nil {
+		p.Methods = make(map[string]Rule)
+	}
+
+	return &p, nil
+}
+
+// Authorize requires the principal to hold at least one of the rule roles
+// and every one of the rule scopes.
+func (p *Policy) Authorize(method string, principal *Principal) error {
+	rule, ok := p.Methods[method]
+	if !ok {
+		if p.DefaultDeny {
+			return fmt.Errorf("%w: no policy for %s", ErrPermissionDenied, method)
+		}
+		return nil
+	}
+
+	if principal == nil {
+		return fmt.Errorf("%w: anonymous caller", ErrPermissionDenied)
+	}
+
+	if len(rule.Roles) > 0 && !slices.ContainsFunc(rule.Roles, principal.HasRole) {
+		return fmt.Errorf("%w: %s requires one of roles %v", ErrPermissionDenied, method, rule.Roles)
+	}
+
+	for _, scope := range rule.Scopes {
+		if !principal.HasScope(scope) {
+			return fmt.Errorf("%w: %s requires scope %s", ErrPermissionDenied, method, scope)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+type principalKey string
+
+const (
+	key principalKey = "principal"
+
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+	MethodMTLS   = "mtls"
+)
+
+type Principal struct {
+	Subject  string
+	TenantID string
+	Roles    []string
+	Scopes   []string
+	Method   string
+}
+
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, key, p)
+}
+
+func PrincipalFromCtx(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(key).(*Principal)
+	return p, ok && p != nil
+}
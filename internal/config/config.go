@@ -1,34 +1,189 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
 	"os"
+	"reflect"
+	"strconv"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/joho/godotenv"
 
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/admin"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/auth"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/health"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/repository"
 	redis "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/repository/cache"
 	postgres "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/repository/database"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tenant"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tlsconfig"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tracing"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
 )
 
 type Config struct {
 	postgres.PostgresCfg
 	redis.RedisCfg
+	repository.BulkheadCfg
+	transport.RateLimitCfg
+	transport.GatewayCfg
+	transport.GRPCServerCfg
+	transport.InterceptorsCfg
+	auth.AuthCfg
+	tenant.TenantCfg
+	tracing.TracingCfg
+	health.HealthCfg
+	tlsconfig.TLSCfg
+	logger.PayloadCfg
+	logger.LevelCfg
+	logger.OutputCfg
+	admin.AdminCfg
 
 	GrpcPort    string `env:"GRPC_PORT"    env-default:"50051"`
 	GatewayPort string `env:"GATEWAY_PORT" env-default:"8080"`
 	Environment string `env:"ENV"          env-default:"prod"`
 }
 
-func New() (*Config, error) {
+const defaultEnvPath = "./config/.env"
+
+// Flags are the command-line options of the server. Every config variable
+// also has a flag named after it (GRPC_PORT -> --grpc-port).
+type Flags struct {
+	ConfigFile  string
+	EnvFile     string
+	PrintConfig bool
+	Overrides   map[string]string
+}
+
+func ParseFlags(name string, args []string, output io.Writer) (Flags, error) {
+	flags := Flags{Overrides: make(map[string]string)}
+
+	set := flag.NewFlagSet(name, flag.ContinueOnError)
+	set.SetOutput(output)
+	set.StringVar(&flags.ConfigFile, "config", os.Getenv("CONFIG_FILE"),
+		"YAML config file, keys are variable names (env CONFIG_FILE)")
+	set.StringVar(&flags.EnvFile, "env-file", os.Getenv("ENV_PATH"),
+		"dotenv file, "+defaultEnvPath+" is used if present (env ENV_PATH)")
+	set.BoolVar(&flags.PrintConfig, "print-config", false,
+		"print the effective config with secrets masked and exit")
+
+	for _, f := range fields(reflect.ValueOf(&Config{}).Elem()) {
+		usage := "overrides " + f.env
+		if f.def != "" {
+			usage += " (default " + strconv.Quote(f.def) + ")"
+		}
+		set.Func(flagName(f.env), usage, func(value string) error {
+			flags.Overrides[f.env] = value
+			return nil
+		})
+	}
+
+	if err := set.Parse(args); err != nil {
+		return flags, err
+	}
+	if set.NArg() > 0 {
+		return flags, fmt.Errorf("unexpected arguments: %v", set.Args())
+	}
+
+	return flags, nil
+}
+
+// Load builds the config from these sources, each overriding the previous:
+//
+//  1. env-default tags
+//  2. the YAML file (--config)
+//  3. the dotenv file (--env-file)
+//  4. process environment
+//  5. command-line flags
+//
+// Sources 2, 3 and 5 are applied by temporarily setting process environment
+// variables, so the env tags remain the single place where names and
+// defaults live.
+// The returned config is filled in even when validation fails so that it
+// can still be printed.
+func Load(flags Flags) (*Config, error) {
 	var cfg Config
-	envPath := os.Getenv("ENV_PATH")
-	if envPath == "" {
-		envPath = "./config/.env"
+
+	known := make(map[string]field)
+	for _, f := range fields(reflect.ValueOf(&cfg).Elem()) {
+		known[f.env] = f
+	}
+
+	values := make(map[string]string)
+	if flags.ConfigFile != "" {
+		yamlValues, err := readYAML(flags.ConfigFile, known)
+		if err != nil {
+			return &cfg, err
+		}
+		maps.Copy(values, yamlValues)
 	}
 
-	err := cleanenv.ReadConfig(envPath, &cfg)
+	envFile := flags.EnvFile
+	if envFile == "" {
+		envFile = defaultEnvPath
+	}
+	dotenv, err := godotenv.Read(envFile)
+	if err != nil && (flags.EnvFile != "" || !errors.Is(err, fs.ErrNotExist)) {
+		return &cfg, fmt.Errorf("failed to read env file %s: %w", envFile, err)
+	}
+	maps.Copy(values, dotenv)
+
+	layered := make(map[string]string, len(values)+len(flags.Overrides))
+	for key, value := range values {
+		if _, set := os.LookupEnv(key); !set {
+			layered[key] = value
+		}
+	}
+	maps.Copy(layered, flags.Overrides)
+
+	restore, err := setenv(layered)
+	defer restore()
 	if err != nil {
 		return &cfg, err
 	}
 
-	return &cfg, nil
+	if err = cleanenv.ReadEnv(&cfg); err != nil {
+		return &cfg, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	return &cfg, cfg.Validate()
+}
+
+// setenv sets the variables and returns a function restoring their
+// previous state.
+func setenv(vars map[string]string) (func(), error) {
+	previous := make(map[string]*string, len(vars))
+	restore := func() {
+		for key, value := range previous {
+			if value == nil {
+				_ = os.Unsetenv(key)
+			} else {
+				_ = os.Setenv(key, *value)
+			}
+		}
+	}
+
+	for key, value := range vars {
+		if old, ok := os.LookupEnv(key); ok {
+			previous[key] = &old
+		} else {
+			previous[key] = nil
+		}
+
+		if err := os.Setenv(key, value); err != nil {
+			return restore, fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+
+	return restore, nil
+}
+
+func New() (*Config, error) {
+	return Load(Flags{})
 }
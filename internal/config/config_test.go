@@ -0,0 +1,200 @@
+package config_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/config"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	yamlFile := writeFile(t, "config.yaml", `
+AUTH_JWT_SECRET: yaml-secret-0123456789abcdef0123456789
+POSTGRES_PASSWORD: yaml-pg-secret
+REDIS_PASSWORD: yaml-redis-secret
+GRPC_PORT: 50001
+GATEWAY_PORT: 8001
+ADMIN_PORT: 9001
+HEALTH_CHECK_INTERVAL: 7s
+GATEWAY_CORS_ALLOWED_ORIGINS: [https://a.example, https://b.example]
+LOG_PAYLOAD_METHOD_POLICIES:
+  /api.OrderService/ListOrders: metadata
+`)
+	envFile := writeFile(t, ".env", `
+# dotenv overrides YAML
+GATEWAY_PORT="8002"
+ADMIN_PORT="9002"
+`)
+	t.Setenv("ADMIN_PORT", "9003")
+
+	flags, err := config.ParseFlags("server", []string{
+		"--config", yamlFile,
+		"--env-file", envFile,
+		"--grpc-port", "50004",
+	}, io.Discard)
+	require.NoError(t, err)
+
+	cfg, err := config.Load(flags)
+	require.NoError(t, err)
+
+	assert.Equal(t, "50004", cfg.GrpcPort, "flag overrides every other source")
+	assert.Equal(t, "8002", cfg.GatewayPort, "dotenv overrides YAML")
+	assert.Equal(t, "9003", cfg.AdminCfg.Port, "environment overrides dotenv")
+	assert.Equal(t, 7*time.Second, cfg.HealthCfg.Interval, "YAML overrides defaults")
+	assert.Equal(t, []string{"https://a.example", "https://b.example"}, cfg.CORSAllowedOrigins)
+	assert.Equal(t, map[string]string{"/api.OrderService/ListOrders": "metadata"}, cfg.MethodPolicies)
+	assert.Equal(t, "prod", cfg.Environment, "default")
+	assert.Equal(t, []string{"platform_admin"}, cfg.CrossTenantRoles, "tenant admins do not cross tenants by default")
+
+	_, set := os.LookupEnv("GRPC_PORT")
+	assert.False(t, set, "process environment is restored")
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Setenv("AUTH_JWT_SECRET", "secret")
+
+	t.Run("explicit env file must exist", func(t *testing.T) {
+		_, err := config.Load(config.Flags{EnvFile: filepath.Join(t.TempDir(), "missing.env")})
+		require.Error(t, err)
+	})
+
+	t.Run("unknown YAML key", func(t *testing.T) {
+		_, err := config.Load(config.Flags{ConfigFile: writeFile(t, "c.yaml", "GRPC_PROT: 1\n")})
+		require.ErrorContains(t, err, "GRPC_PROT")
+	})
+
+	t.Run("malformed duration", func(t *testing.T) {
+		_, err := config.Load(config.Flags{Overrides: map[string]string{"HEALTH_CHECK_INTERVAL": "5"}})
+		require.ErrorContains(t, err, "HEALTH_CHECK_INTERVAL")
+	})
+
+	t.Run("all violations are reported", func(t *testing.T) {
+		_, err := config.Load(config.Flags{Overrides: map[string]string{
+			"GRPC_PORT":         "70000",
+			"GATEWAY_PORT":      "http",
+			"AUTH_JWT_SECRET":   "",
+			"POSTGRES_PASSWORD": "",
+			"TLS_ENABLED":       "true",
+			"LOG_FORMAT":        "xml",
+			"GRPC_INTERCEPTORS": "recovery,tracing",
+		}})
+		require.Error(t, err)
+
+		for _, env := range []string{
+			"GRPC_PORT", "GATEWAY_PORT", "AUTH_ENABLED", "POSTGRES_PASSWORD",
+			"TLS_CERT_FILE", "TLS_KEY_FILE", "LOG_FORMAT", "GRPC_INTERCEPTORS",
+		} {
+			assert.ErrorContains(t, err, env)
+		}
+	})
+
+	t.Run("interceptor chain", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			interceptors string
+			want         string
+		}{
+			"auth missing":       {"recovery,tenant,rate_limit", "must include auth"},
+			"rate limit missing": {"recovery,auth,tenant", "must include rate_limit"},
+			"tenant before auth": {"recovery,tenant,auth,rate_limit", "auth must come before tenant"},
+			"rate limit first":   {"rate_limit,auth,tenant", "auth must come before rate_limit"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := config.Load(config.Flags{Overrides: map[string]string{
+					"AUTH_ENABLED":       "true",
+					"RATE_LIMIT_ENABLED": "true",
+					"GRPC_INTERCEPTORS":  tc.interceptors,
+				}})
+				require.ErrorContains(t, err, tc.want)
+			})
+		}
+	})
+
+	t.Run("invalid trusted proxy", func(t *testing.T) {
+		_, err := config.Load(config.Flags{Overrides: map[string]string{
+			"RATE_LIMIT_ENABLED":         "true",
+			"RATE_LIMIT_TRUSTED_PROXIES": "10.0.0.0/8,proxy.local",
+		}})
+		require.ErrorContains(t, err, "RATE_LIMIT_TRUSTED_PROXIES")
+	})
+
+	t.Run("single port client certificates", func(t *testing.T) {
+		_, err := config.Load(config.Flags{Overrides: map[string]string{
+			"GATEWAY_SINGLE_PORT":     "true",
+			"TLS_REQUIRE_CLIENT_CERT": "true",
+			"AUTH_MTLS_ENABLED":       "true",
+		}})
+		require.ErrorContains(t, err, "TLS_REQUIRE_CLIENT_CERT: cannot be enforced with GATEWAY_SINGLE_PORT")
+		require.ErrorContains(t, err, "AUTH_MTLS_ENABLED: requires TLS_GATEWAY_HTTPS")
+	})
+
+	t.Run("default passwords in prod", func(t *testing.T) {
+		_, err := config.Load(config.Flags{Overrides: map[string]string{"ENV": "prod"}})
+		require.ErrorContains(t, err, "POSTGRES_PASSWORD: must not be the default in prod")
+		require.ErrorContains(t, err, "REDIS_PASSWORD: must not be the default in prod")
+
+		_, err = config.Load(config.Flags{Overrides: map[string]string{"ENV": "dev"}})
+		require.NoError(t, err)
+	})
+
+	t.Run("weak JWT secret in prod", func(t *testing.T) {
+		_, err := config.Load(config.Flags{Overrides: map[string]string{"ENV": "prod", "AUTH_JWT_SECRET": "change-me"}})
+		require.ErrorContains(t, err, "AUTH_JWT_SECRET: must not be the example value in prod")
+		require.ErrorContains(t, err, "AUTH_JWT_SECRET: must be at least 32 bytes in prod")
+
+		_, err = config.Load(config.Flags{Overrides: map[string]string{"ENV": "dev", "AUTH_JWT_SECRET": "change-me"}})
+		require.NoError(t, err)
+	})
+
+	t.Run("unexpected arguments", func(t *testing.T) {
+		_, err := config.ParseFlags("server", []string{"extra"}, io.Discard)
+		require.Error(t, err)
+	})
+}
+
+func TestConfig_Print(t *testing.T) {
+	cfg, err := config.Load(config.Flags{Overrides: map[string]string{
+		"AUTH_JWT_SECRET":   "super-secret-0123456789abcdef0123456789",
+		"POSTGRES_PASSWORD": "pg-secret",
+		"REDIS_PASSWORD":    "",
+		"LOG_REDACT_FIELDS": "customer_id,item",
+	}})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.Print(&out))
+
+	assert.NotContains(t, out.String(), "super-secret")
+	assert.Contains(t, out.String(), `AUTH_JWT_SECRET="******"`+"\n")
+	assert.Contains(t, out.String(), `POSTGRES_PASSWORD="******"`+"\n")
+	assert.Contains(t, out.String(), `REDIS_PASSWORD=""`+"\n", "empty secrets are shown as empty")
+	assert.Contains(t, out.String(), `LOG_REDACT_FIELDS="customer_id,item"`+"\n")
+	assert.Contains(t, out.String(), `BULKHEAD_QUEUE_TIMEOUT="500ms"`+"\n")
+
+	t.Run("printed config loads back", func(t *testing.T) {
+		printed := writeFile(t, "printed.env", out.String())
+		t.Setenv("AUTH_JWT_SECRET", "super-secret-0123456789abcdef0123456789")
+		t.Setenv("POSTGRES_PASSWORD", "pg-secret")
+
+		reloaded, err := config.Load(config.Flags{EnvFile: printed})
+		require.NoError(t, err)
+
+		var reprinted bytes.Buffer
+		require.NoError(t, reloaded.Print(&reprinted))
+		assert.Equal(t, out.String(), reprinted.String())
+	})
+}
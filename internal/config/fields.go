@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const secretMask = "******"
+
+type field struct {
+	env       string
+	def       string
+	separator string
+	secret    bool
+	value     reflect.Value
+}
+
+// fields lists the variables of v in declaration order, descending into
+// embedded component configs.
+func fields(v reflect.Value) []field {
+	var out []field
+
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			out = append(out, fields(v.Field(i))...)
+			continue
+		}
+
+		env := sf.Tag.Get("env")
+		if env == "" || !sf.IsExported() {
+			continue
+		}
+
+		separator := sf.Tag.Get("env-separator")
+		if separator == "" {
+			separator = ","
+		}
+
+		out = append(out, field{
+			env:       env,
+			def:       sf.Tag.Get("env-default"),
+			separator: separator,
+			secret:    sf.Tag.Get("secret") == "true",
+			value:     v.Field(i),
+		})
+	}
+
+	return out
+}
+
+// String formats the value the way cleanenv parses it back.
+func (f field) String() string {
+	switch v := f.value.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case []string:
+		return strings.Join(v, f.separator)
+	case map[string]string:
+		pairs := make([]string, 0, len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			pairs = append(pairs, k+":"+v[k])
+		}
+		return strings.Join(pairs, f.separator)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Print writes the effective config in dotenv format with secrets masked.
+func (c *Config) Print(w io.Writer) error {
+	for _, f := range fields(reflect.ValueOf(c).Elem()) {
+		value := f.String()
+		if f.secret && value != "" {
+			value = secretMask
+		}
+
+		if _, err := fmt.Fprintf(w, "%s=%q\n", f.env, value); err != nil {
+			return fmt.Errorf("failed to print config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func flagName(env string) string {
+	return strings.ToLower(strings.ReplaceAll(env, "_", "-"))
+}
+
+// readYAML reads a flat YAML mapping of variable names to values. Lists and
+// maps are accepted for list and map variables. Unknown keys are rejected
+// so that typos don't silently fall back to defaults.
+func readYAML(path string, known map[string]field) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]any
+	if err = yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		f, ok := known[key]
+		if !ok {
+			return nil, fmt.Errorf("config file %s: unknown key %s", path, key)
+		}
+		values[key] = yamlValue(value, f.separator)
+	}
+
+	return values, nil
+}
+
+func yamlValue(value any, separator string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, separator)
+	case map[string]any:
+		pairs := make([]string, 0, len(v))
+		for k, item := range v {
+			pairs = append(pairs, k+":"+fmt.Sprint(item))
+		}
+		slices.Sort(pairs)
+		return strings.Join(pairs, separator)
+	default:
+		return fmt.Sprint(v)
+	}
+}
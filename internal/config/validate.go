@@ -0,0 +1,220 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tracing"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+)
+
+const (
+	EnvDev  = "dev"
+	EnvProd = "prod"
+
+	defaultPostgresPassword = "postgres"
+	defaultRedisPassword    = "redis"
+	exampleJWTSecret        = "change-me"
+	// minJWTSecretLen matches the 256-bit key size HS256 is defined for.
+	minJWTSecretLen = 32
+)
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, env, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: %s", env, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (v *validator) oneOf(env, value string, allowed ...string) {
+	v.check(slices.Contains(allowed, value), env, "%q is not one of %v", value, allowed)
+}
+
+func (v *validator) port(env, value string) {
+	port, err := strconv.Atoi(value)
+	v.check(err == nil && port > 0 && port <= 65535, env, "%q is not a valid port", value)
+}
+
+func (v *validator) required(env, value string) {
+	v.check(value != "", env, "is required")
+}
+
+func (v *validator) positive(env string, value time.Duration) {
+	v.check(value > 0, env, "must be positive, got %s", value)
+}
+
+func (v *validator) nonNegative(env string, value time.Duration) {
+	v.check(value >= 0, env, "must not be negative, got %s", value)
+}
+
+// Validate reports every invalid setting at once, so a broken deployment
+// is fixed in one round instead of one variable per restart.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.oneOf("ENV", c.Environment, EnvDev, EnvProd)
+	c.validateListeners(v)
+	c.validateStorage(v)
+	c.validateSecurity(v)
+	c.validateLogging(v)
+
+	v.oneOf("GATEWAY_MODE", c.GatewayCfg.Mode, transport.GatewayModeInProcess, transport.GatewayModeDial)
+	v.check(c.MaxBodyBytes > 0, "GATEWAY_MAX_BODY_BYTES", "must be positive")
+	v.nonNegative("GATEWAY_CORS_MAX_AGE", c.CORSMaxAge)
+
+	v.check(c.MaxRecvMsgSize > 0, "GRPC_MAX_RECV_MSG_SIZE", "must be positive")
+	v.check(c.MaxSendMsgSize > 0, "GRPC_MAX_SEND_MSG_SIZE", "must be positive")
+	v.check(c.MaxConcurrentStreams > 0, "GRPC_MAX_CONCURRENT_STREAMS", "must be positive")
+	v.positive("GRPC_KEEPALIVE_TIME", c.KeepaliveTime)
+	v.positive("GRPC_KEEPALIVE_TIMEOUT", c.KeepaliveTimeout)
+	v.nonNegative("GRPC_KEEPALIVE_MIN_TIME", c.KeepaliveMinTime)
+	v.nonNegative("GRPC_MAX_CONNECTION_IDLE", c.MaxConnectionIdle)
+	v.nonNegative("GRPC_MAX_CONNECTION_AGE", c.MaxConnectionAge)
+	v.nonNegative("GRPC_MAX_CONNECTION_AGE_GRACE", c.MaxConnectionAgeGrace)
+	c.validateInterceptors(v)
+
+	if c.RateLimitCfg.Enabled {
+		v.oneOf("RATE_LIMIT_BACKEND", c.Backend, transport.RateLimitBackendLocal, transport.RateLimitBackendRedis)
+		v.check(c.Rate > 0, "RATE_LIMIT_RPS", "must be positive")
+		v.check(c.Burst > 0, "RATE_LIMIT_BURST", "must be positive")
+		if _, err := transport.ParseTrustedProxies(c.TrustedProxies); err != nil {
+			v.check(false, "RATE_LIMIT_TRUSTED_PROXIES", "%v", err)
+		}
+		for _, key := range c.KeyBy {
+			v.oneOf("RATE_LIMIT_KEY_BY", key,
+				transport.RateLimitKeyIdentity, transport.RateLimitKeyIP, transport.RateLimitKeyMethod)
+		}
+	}
+
+	v.check(c.PointLimit > 0, "BULKHEAD_POINT_LIMIT", "must be positive")
+	v.check(c.ListLimit > 0, "BULKHEAD_LIST_LIMIT", "must be positive")
+	v.positive("BULKHEAD_QUEUE_TIMEOUT", c.QueueTimeout)
+
+	v.positive("HEALTH_CHECK_INTERVAL", c.HealthCfg.Interval)
+	v.positive("HEALTH_CHECK_TIMEOUT", c.HealthCfg.Timeout)
+	v.nonNegative("HEALTH_SHUTDOWN_DELAY", c.ShutdownDelay)
+
+	if c.TracingCfg.Enabled {
+		v.oneOf("TRACING_EXPORTER", c.Exporter, tracing.ExporterOTLP, tracing.ExporterStdout)
+		v.check(c.SampleRatio >= 0 && c.SampleRatio <= 1, "TRACING_SAMPLE_RATIO", "must be between 0 and 1")
+	}
+
+	if len(v.errs) > 0 {
+		return fmt.Errorf("invalid config:\n%w", errors.Join(v.errs...))
+	}
+
+	return nil
+}
+
+func (c *Config) validateListeners(v *validator) {
+	v.port("GRPC_PORT", c.GrpcPort)
+	v.port("GATEWAY_PORT", c.GatewayPort)
+	if !c.SinglePort {
+		v.check(c.GrpcPort != c.GatewayPort, "GRPC_PORT", "must differ from GATEWAY_PORT unless GATEWAY_SINGLE_PORT is set")
+	}
+
+	if c.AdminCfg.Enabled {
+		v.port("ADMIN_PORT", c.AdminCfg.Port)
+		v.check(c.AdminCfg.Port != c.GatewayPort && c.AdminCfg.Port != c.GrpcPort,
+			"ADMIN_PORT", "must differ from GRPC_PORT and GATEWAY_PORT")
+	}
+}
+
+func (c *Config) validateStorage(v *validator) {
+	v.required("POSTGRES_HOST", c.PostgresCfg.Host)
+	v.port("POSTGRES_PORT", c.PostgresCfg.Port)
+	v.required("POSTGRES_USER", c.PostgresCfg.User)
+	v.required("POSTGRES_PASSWORD", c.PostgresCfg.Password)
+	v.required("POSTGRES_DB", c.DBName)
+	v.oneOf("POSTGRES_SSLMODE", c.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+
+	v.required("REDIS_HOST", c.RedisCfg.Host)
+	v.port("REDIS_PORT", c.RedisCfg.Port)
+
+	if c.Environment == EnvProd {
+		v.check(c.PostgresCfg.Password != defaultPostgresPassword, "POSTGRES_PASSWORD", "must not be the default in %s", EnvProd)
+		v.check(c.RedisCfg.Password != defaultRedisPassword, "REDIS_PASSWORD", "must not be the default in %s", EnvProd)
+	}
+}
+
+func (c *Config) validateSecurity(v *validator) {
+	if c.AuthCfg.Enabled {
+		v.check(c.JWTSecret != "" || c.JWKSFile != "" || c.APIKeysFile != "" || c.MTLSEnabled,
+			"AUTH_ENABLED", "requires AUTH_JWT_SECRET, AUTH_JWKS_FILE, AUTH_API_KEYS_FILE or AUTH_MTLS_ENABLED")
+	}
+	if c.Environment == EnvProd && c.JWTSecret != "" {
+		v.check(c.JWTSecret != exampleJWTSecret, "AUTH_JWT_SECRET", "must not be the example value in %s", EnvProd)
+		v.check(len(c.JWTSecret) >= minJWTSecretLen, "AUTH_JWT_SECRET",
+			"must be at least %d bytes in %s", minJWTSecretLen, EnvProd)
+	}
+	if c.MTLSEnabled {
+		v.check(c.TLSCfg.Enabled && c.ClientCAFile != "",
+			"AUTH_MTLS_ENABLED", "requires TLS_ENABLED and TLS_CLIENT_CA_FILE")
+	}
+
+	if c.TLSCfg.Enabled || c.GatewayHTTPS {
+		v.required("TLS_CERT_FILE", c.CertFile)
+		v.required("TLS_KEY_FILE", c.KeyFile)
+	}
+	if c.TLSCfg.Enabled && c.RequireClientCert {
+		v.required("TLS_CLIENT_CA_FILE", c.ClientCAFile)
+	}
+	if c.SinglePort {
+		// REST clients share the port and carry no certificate
+		v.check(!c.RequireClientCert, "TLS_REQUIRE_CLIENT_CERT", "cannot be enforced with GATEWAY_SINGLE_PORT")
+		v.check(!c.MTLSEnabled || c.GatewayHTTPS, "AUTH_MTLS_ENABLED", "requires TLS_GATEWAY_HTTPS with GATEWAY_SINGLE_PORT")
+	}
+	v.nonNegative("TLS_RELOAD_INTERVAL", c.ReloadInterval)
+}
+
+// validateInterceptors rejects chains that would silently disable an enabled
+// feature or let tenant resolution and rate limiting run before the caller is
+// authenticated.
+func (c *Config) validateInterceptors(v *validator) {
+	if _, _, err := transport.Chain(c.Interceptors, nil); err != nil {
+		v.check(false, "GRPC_INTERCEPTORS", "%v", err)
+		return
+	}
+
+	if c.RateLimitCfg.Enabled {
+		v.check(slices.Contains(c.Interceptors, transport.InterceptorRateLimit),
+			"GRPC_INTERCEPTORS", "must include %s when RATE_LIMIT_ENABLED is set", transport.InterceptorRateLimit)
+	}
+
+	if !c.AuthCfg.Enabled {
+		return
+	}
+	auth := slices.Index(c.Interceptors, transport.InterceptorAuth)
+	if auth < 0 {
+		v.check(false, "GRPC_INTERCEPTORS", "must include %s when AUTH_ENABLED is set", transport.InterceptorAuth)
+		return
+	}
+	for _, name := range []string{transport.InterceptorTenant, transport.InterceptorRateLimit} {
+		if i := slices.Index(c.Interceptors, name); i >= 0 {
+			v.check(auth < i, "GRPC_INTERCEPTORS", "%s must come before %s", transport.InterceptorAuth, name)
+		}
+	}
+}
+
+func (c *Config) validateLogging(v *validator) {
+	v.oneOf("LOG_FORMAT", c.Format, logger.FormatJSON, logger.FormatConsole)
+	v.check(len(c.Outputs) > 0, "LOG_OUTPUTS", "at least one output is required")
+	if c.SamplingEnabled {
+		v.check(c.SamplingInitial > 0, "LOG_SAMPLING_INITIAL", "must be positive")
+		v.check(c.SamplingThereafter > 0, "LOG_SAMPLING_THEREAFTER", "must be positive")
+	}
+
+	payloadPolicies := []string{logger.PayloadFull, logger.PayloadMetadata, logger.PayloadNone}
+	v.oneOf("LOG_PAYLOAD_POLICY", c.Policy, payloadPolicies...)
+	for _, policy := range c.MethodPolicies {
+		v.oneOf("LOG_PAYLOAD_METHOD_POLICIES", policy, payloadPolicies...)
+	}
+	v.check(c.MaxPayloadBytes > 0, "LOG_MAX_PAYLOAD_BYTES", "must be positive")
+}
@@ -0,0 +1,244 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	CheckMethod = "/grpc.health.v1.Health/Check"
+	WatchMethod = "/grpc.health.v1.Health/Watch"
+
+	statusOK       = "ok"
+	statusPending  = "pending"
+	statusFailed   = "unavailable"
+	statusShutdown = "shutting down"
+)
+
+// PublicMethods are the grpc.health.v1 methods probes and load balancers
+// call without credentials.
+func PublicMethods() []string {
+	return []string{CheckMethod, WatchMethod}
+}
+
+type HealthCfg struct {
+	Interval      time.Duration `env:"HEALTH_CHECK_INTERVAL" env-default:"5s"`
+	Timeout       time.Duration `env:"HEALTH_CHECK_TIMEOUT"  env-default:"2s"`
+	ShutdownDelay time.Duration `env:"HEALTH_SHUTDOWN_DELAY" env-default:"0s"`
+}
+
+type Check func(ctx context.Context) error
+
+// Health tracks dependency status and publishes it both through the standard
+// grpc.health.v1 service (one service name per dependency, "" for overall)
+// and through /healthz and /readyz.
+type Health struct {
+	cfg    HealthCfg
+	server *health.Server
+
+	mu           sync.RWMutex
+	checks       map[string]Check
+	status       map[string]string
+	shuttingDown bool
+	lastRun      time.Time
+}
+
+func New(cfg HealthCfg) *Health {
+	h := &Health{
+		cfg:     cfg,
+		server:  health.NewServer(),
+		checks:  make(map[string]Check),
+		status:  make(map[string]string),
+		lastRun: time.Now(),
+	}
+	h.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return h
+}
+
+func (h *Health) Server() healthpb.HealthServer {
+	return h.server
+}
+
+// AddCheck registers a dependency that is probed periodically by Run.
+func (h *Health) AddCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks[name] = check
+	h.setLocked(name, statusPending)
+}
+
+// AddFlag registers a dependency that stays not ready until MarkReady.
+func (h *Health) AddFlag(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.setLocked(name, statusPending)
+}
+
+func (h *Health) MarkReady(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.setLocked(name, statusOK)
+}
+
+func (h *Health) Run(ctx context.Context) {
+	h.checkAll(ctx)
+
+	ticker := time.NewTicker(h.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.checkAll(ctx)
+		}
+	}
+}
+
+// Shutdown reports every dependency as not serving so load balancers stop
+// routing new requests, then waits ShutdownDelay for them to notice.
+func (h *Health) Shutdown() {
+	h.mu.Lock()
+	h.shuttingDown = true
+	h.mu.Unlock()
+
+	h.server.Shutdown()
+
+	if h.cfg.ShutdownDelay > 0 {
+		time.Sleep(h.cfg.ShutdownDelay)
+	}
+}
+
+func (h *Health) checkAll(ctx context.Context) {
+	h.mu.RLock()
+	checks := make(map[string]Check, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	h.mu.RUnlock()
+
+	results := make(map[string]string, len(checks))
+	for name, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, h.cfg.Timeout)
+		err := check(checkCtx)
+		cancel()
+
+		// /readyz is unauthenticated, so the error text with hosts and
+		// driver details goes to the log only
+		results[name] = statusOK
+		if err != nil {
+			results[name] = statusFailed
+			logger.GetLoggerFromCtx(ctx).Named("health").Warn(ctx, "health check failed",
+				zap.String("dependency", name),
+				zap.Error(err),
+			)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for name, result := range results {
+		h.setLocked(name, result)
+	}
+	h.lastRun = time.Now()
+}
+
+func (h *Health) setLocked(name, result string) {
+	if h.shuttingDown {
+		return
+	}
+
+	h.status[name] = result
+	h.server.SetServingStatus(name, servingStatus(result == statusOK))
+	h.server.SetServingStatus("", servingStatus(h.readyLocked()))
+}
+
+func (h *Health) readyLocked() bool {
+	if h.shuttingDown {
+		return false
+	}
+
+	for _, result := range h.status {
+		if result != statusOK {
+			return false
+		}
+	}
+
+	return true
+}
+
+func servingStatus(ok bool) healthpb.HealthCheckResponse_ServingStatus {
+	if ok {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+type report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// LivenessHandler fails only when the check loop itself is stuck, a broken
+// dependency makes the service not ready but does not warrant a restart.
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		h.mu.RLock()
+		stale := time.Since(h.lastRun) > 3*h.cfg.Interval+h.cfg.Timeout
+		h.mu.RUnlock()
+
+		if stale {
+			writeReport(w, http.StatusServiceUnavailable, report{Status: "check loop stalled"})
+			return
+		}
+
+		writeReport(w, http.StatusOK, report{Status: statusOK})
+	})
+}
+
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		h.mu.RLock()
+		rep := report{Status: "ready", Checks: make(map[string]string, len(h.status))}
+		for name, result := range h.status {
+			rep.Checks[name] = result
+		}
+		ready := h.readyLocked()
+		shuttingDown := h.shuttingDown
+		h.mu.RUnlock()
+
+		switch {
+		case shuttingDown:
+			rep.Status = statusShutdown
+		case !ready:
+			rep.Status = "not ready"
+		}
+
+		code := http.StatusOK
+		if !ready {
+			code = http.StatusServiceUnavailable
+		}
+		writeReport(w, code, rep)
+	})
+}
+
+func writeReport(w http.ResponseWriter, code int, rep report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(rep)
+}
@@ -0,0 +1,128 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/auth"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/health"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger/loggertest"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func grpcStatus(t *testing.T, h *health.Health, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	resp, err := h.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+
+	return resp.GetStatus()
+}
+
+func readyCode(h *health.Health) int {
+	rec := httptest.NewRecorder()
+	h.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	return rec.Code
+}
+
+func TestHealth(t *testing.T) {
+	ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var redisDown atomic.Bool
+	h := health.New(health.HealthCfg{Interval: 10 * time.Millisecond, Timeout: time.Second})
+	h.AddCheck("postgres", func(context.Context) error { return nil })
+	h.AddCheck("redis", func(context.Context) error {
+		if redisDown.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	h.AddFlag("cache_warmup")
+
+	assert.Equal(t, http.StatusServiceUnavailable, readyCode(h))
+
+	go h.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		return grpcStatus(t, h, "redis") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, h, ""), "warm-up not finished")
+
+	h.MarkReady("cache_warmup")
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, grpcStatus(t, h, ""))
+	assert.Equal(t, http.StatusOK, readyCode(h))
+
+	redisDown.Store(true)
+	require.Eventually(t, func() bool { return readyCode(h) == http.StatusServiceUnavailable }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, h, "redis"))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, grpcStatus(t, h, "postgres"))
+
+	rec := httptest.NewRecorder()
+	h.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Contains(t, rec.Body.String(), `"redis":"unavailable"`)
+	assert.NotContains(t, rec.Body.String(), "connection refused", "error details stay in the log")
+
+	rec = httptest.NewRecorder()
+	h.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "dependency failure must not fail liveness")
+
+	redisDown.Store(false)
+	require.Eventually(t, func() bool { return readyCode(h) == http.StatusOK }, time.Second, 5*time.Millisecond)
+
+	h.Shutdown()
+	assert.Equal(t, http.StatusServiceUnavailable, readyCode(h))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, h, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, h, "postgres"))
+}
+
+func TestPublicMethods_WithoutCredentials(t *testing.T) {
+	ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+
+	authenticator, err := auth.NewAuthenticator(auth.AuthCfg{JWTSecret: "test-secret"})
+	require.NoError(t, err)
+
+	h := health.New(health.HealthCfg{Interval: time.Second, Timeout: time.Second})
+	h.AddCheck("postgres", func(context.Context) error { return nil })
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.AuthInterceptor(authenticator, health.PublicMethods())),
+		grpc.StreamInterceptor(auth.AuthStreamInterceptor(authenticator, health.PublicMethods())),
+	)
+	healthpb.RegisterHealthServer(srv, h.Server())
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	client := healthpb.NewHealthClient(conn)
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	watchCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	stream, err := client.Watch(watchCtx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err, "Watch must not require credentials")
+}
@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "order_service"
+
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheOK    = "ok"
+	CacheError = "error"
+)
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	rpcRequests     *prometheus.CounterVec
+	rpcDuration     *prometheus.HistogramVec
+	cacheOperations *prometheus.CounterVec
+	cacheWarmUp     prometheus.Gauge
+	ordersCreated   prometheus.Counter
+	ordersDeleted   prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		rpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "requests_total",
+			Help:      "Total number of gRPC requests by method and status code.",
+		}, []string{"method", "code"}),
+
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "request_duration_seconds",
+			Help:      "gRPC request latency by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+
+		cacheOperations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "operations_total",
+			Help:      "Redis cache operations by operation and result (hit, miss, ok, error).",
+		}, []string{"operation", "result"}),
+
+		cacheWarmUp: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "warmup_orders",
+			Help:      "Number of orders loaded into the cache by the last warm-up.",
+		}),
+
+		ordersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orders_created_total",
+			Help:      "Total number of created orders.",
+		}),
+
+		ordersDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orders_deleted_total",
+			Help:      "Total number of deleted orders.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.rpcRequests,
+		m.rpcDuration,
+		m.cacheOperations,
+		m.cacheWarmUp,
+		m.ordersCreated,
+		m.ordersDeleted,
+	)
+
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) Register(c prometheus.Collector) error {
+	return m.registry.Register(c)
+}
+
+func (m *Metrics) ObserveCache(operation, result string) {
+	if m == nil {
+		return
+	}
+	m.cacheOperations.WithLabelValues(operation, result).Inc()
+}
+
+func (m *Metrics) SetCacheWarmUp(orders int) {
+	if m == nil {
+		return
+	}
+	m.cacheWarmUp.Set(float64(orders))
+}
+
+func (m *Metrics) OrderCreated() {
+	if m == nil {
+		return
+	}
+	m.ordersCreated.Inc()
+}
+
+func (m *Metrics) OrderDeleted() {
+	if m == nil {
+		return
+	}
+	m.ordersDeleted.Inc()
+}
@@ -0,0 +1,77 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+func TestMetricsInterceptor(t *testing.T) {
+	m := metrics.New()
+	interceptor := metrics.MetricsInterceptor(m)
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.OrderService/GetOrder"}
+
+	_, err := interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+
+	_, err = interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+	require.Error(t, err)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `order_service_grpc_requests_total{code="OK",method="/api.OrderService/GetOrder"} 1`)
+	assert.Contains(t, body, `order_service_grpc_requests_total{code="NotFound",method="/api.OrderService/GetOrder"} 1`)
+	assert.Contains(t, body, `order_service_grpc_request_duration_seconds_count{method="/api.OrderService/GetOrder"} 2`)
+}
+
+func TestMetrics_CacheAndBusinessCounters(t *testing.T) {
+	m := metrics.New()
+
+	m.ObserveCache("get", metrics.CacheHit)
+	m.ObserveCache("get", metrics.CacheHit)
+	m.ObserveCache("get", metrics.CacheMiss)
+	m.SetCacheWarmUp(42)
+	m.OrderCreated()
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `order_service_cache_operations_total{operation="get",result="hit"} 2`)
+	assert.Contains(t, body, `order_service_cache_operations_total{operation="get",result="miss"} 1`)
+	assert.Contains(t, body, `order_service_cache_warmup_orders 42`)
+	assert.Contains(t, body, `order_service_orders_created_total 1`)
+}
+
+func TestMetrics_NilSafe(t *testing.T) {
+	var m *metrics.Metrics
+
+	assert.NotPanics(t, func() {
+		m.ObserveCache("get", metrics.CacheHit)
+		m.SetCacheWarmUp(1)
+		m.OrderCreated()
+		m.OrderDeleted()
+	})
+}
@@ -0,0 +1,110 @@
+package patterns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrBulkheadFull = errors.New("bulkhead is full")
+)
+
+type Bulkhead struct {
+	sem          chan struct{}
+	queueTimeout time.Duration
+}
+
+func NewBulkhead(maxConcurrent int, queueTimeout time.Duration) *Bulkhead {
+	return &Bulkhead{
+		sem:          make(chan struct{}, max(maxConcurrent, 1)),
+		queueTimeout: queueTimeout,
+	}
+}
+
+func (b *Bulkhead) Execute(ctx context.Context, operation func() error) error {
+	if err := b.acquire(ctx); err != nil {
+		return err
+	}
+	defer b.release()
+
+	return operation()
+}
+
+func (b *Bulkhead) InUse() int {
+	return len(b.sem)
+}
+
+func (b *Bulkhead) Capacity() int {
+	return cap(b.sem)
+}
+
+func (b *Bulkhead) acquire(ctx context.Context) error {
+	select {
+	case b.sem <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if b.queueTimeout <= 0 {
+		return ErrBulkheadFull
+	}
+
+	timer := time.NewTimer(b.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case b.sem <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrBulkheadFull
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Bulkhead) release() {
+	<-b.sem
+}
+
+type BulkheadLimit struct {
+	MaxConcurrent int
+	QueueTimeout  time.Duration
+}
+
+type BulkheadGroup struct {
+	bulkheads map[string]*Bulkhead
+}
+
+func NewBulkheadGroup(limits map[string]BulkheadLimit) *BulkheadGroup {
+	bulkheads := make(map[string]*Bulkhead, len(limits))
+	for class, limit := range limits {
+		bulkheads[class] = NewBulkhead(limit.MaxConcurrent, limit.QueueTimeout)
+	}
+
+	return &BulkheadGroup{
+		bulkheads: bulkheads,
+	}
+}
+
+func (g *BulkheadGroup) Execute(ctx context.Context, class string, operation func() error) error {
+	bulkhead, ok := g.bulkheads[class]
+	if !ok {
+		return fmt.Errorf("unknown bulkhead class %q", class)
+	}
+
+	if err := bulkhead.Execute(ctx, operation); err != nil {
+		if errors.Is(err, ErrBulkheadFull) {
+			return fmt.Errorf("%s: %w", class, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (g *BulkheadGroup) Get(class string) (*Bulkhead, bool) {
+	bulkhead, ok := g.bulkheads[class]
+	return bulkhead, ok
+}
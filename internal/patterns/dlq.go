@@ -1,60 +1,186 @@
 package patterns
 
-import "sync"
+import (
+	"context"
+	"sync"
+	"time"
+)
 
-type DeadLetterQueue struct {
-	queue  []string
+type DLQEntry[T any] struct {
+	Message  T
+	Err      error
+	Attempts int
+	FailedAt time.Time
+}
+
+type DeadLetterQueue[T any] struct {
+	queue  []DLQEntry[T]
 	mu     sync.RWMutex
 	failed int
 }
 
-func NewDeadLetterQueue() *DeadLetterQueue {
-	return &DeadLetterQueue{
-		queue:  make([]string, 0),
+func NewDeadLetterQueue[T any]() *DeadLetterQueue[T] {
+	return &DeadLetterQueue[T]{
+		queue:  make([]DLQEntry[T], 0),
 		mu:     sync.RWMutex{},
 		failed: 0,
 	}
 }
 
-func (l *DeadLetterQueue) GetMessages() []string {
+func (l *DeadLetterQueue[T]) GetMessages() []T {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	messages := make([]T, 0, len(l.queue))
+	for _, entry := range l.queue {
+		messages = append(messages, entry.Message)
+	}
+
+	return messages
+}
+
+func (l *DeadLetterQueue[T]) GetEntries() []DLQEntry[T] {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
-	return l.queue
+	entries := make([]DLQEntry[T], len(l.queue))
+	copy(entries, l.queue)
+
+	return entries
+}
+
+func (l *DeadLetterQueue[T]) Add(msg ...T) {
+	now := time.Now()
+	entries := make([]DLQEntry[T], 0, len(msg))
+	for _, m := range msg {
+		entries = append(entries, DLQEntry[T]{Message: m, FailedAt: now})
+	}
+
+	l.AddEntries(entries...)
 }
 
-func (l *DeadLetterQueue) Add(msg ...string) {
+func (l *DeadLetterQueue[T]) AddWithError(msg T, err error, attempts int) {
+	l.AddEntries(DLQEntry[T]{
+		Message:  msg,
+		Err:      err,
+		Attempts: attempts,
+		FailedAt: time.Now(),
+	})
+}
+
+func (l *DeadLetterQueue[T]) AddEntries(entries ...DLQEntry[T]) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
 	l.failed++
-	l.queue = append(l.queue, msg...)
+	l.queue = append(l.queue, entries...)
 }
 
-func (l *DeadLetterQueue) Clear() {
+func (l *DeadLetterQueue[T]) Clear() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	l.queue = make([]string, 0)
+	l.queue = make([]DLQEntry[T], 0)
 }
 
-func (l *DeadLetterQueue) Size() int {
+func (l *DeadLetterQueue[T]) Size() int {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 	return len(l.queue)
 }
 
-func (l *DeadLetterQueue) FailedAmount() int {
+func (l *DeadLetterQueue[T]) FailedAmount() int {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 	return l.failed
 }
 
-func ProcessWithDLQ(messages []string, operation func(msg string) error, dlq *DeadLetterQueue) {
+func ProcessWithDLQ[T any](messages []T, operation func(msg T) error, dlq *DeadLetterQueue[T]) {
 	for _, message := range messages {
 		err := operation(message)
 		if err != nil {
-			dlq.Add(message)
+			dlq.AddWithError(message, err, 1)
+		}
+	}
+}
+
+type ProcessorCfg struct {
+	Workers    int
+	MaxRetries int
+	BaseDelay  time.Duration
+	Timeout    time.Duration
+}
+
+// ProcessConcurrently runs operation over messages with at most cfg.Workers
+// goroutines, retrying every message with exponential backoff and bounding each
+// attempt by cfg.Timeout. Messages that still fail, as well as messages that were
+// not started because ctx was cancelled, are dead-lettered in input order.
+func ProcessConcurrently[T any](
+	ctx context.Context,
+	messages []T,
+	operation func(ctx context.Context, msg T) error,
+	dlq *DeadLetterQueue[T],
+	cfg ProcessorCfg,
+) error {
+	workers := max(cfg.Workers, 1)
+	maxRetries := max(cfg.MaxRetries, 1)
+
+	failed := make([]*DLQEntry[T], len(messages))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for idx := range jobs {
+				msg := messages[idx]
+				if ctx.Err() != nil {
+					failed[idx] = &DLQEntry[T]{Message: msg, Err: ctx.Err(), FailedAt: time.Now()}
+					continue
+				}
+
+				attempts := 0
+
+				err := RetryContext(ctx, func() error {
+					attempts++
+					if cfg.Timeout <= 0 {
+						return operation(ctx, msg)
+					}
+					return TimeoutContext(ctx, func(opCtx context.Context) error {
+						return operation(opCtx, msg)
+					}, cfg.Timeout)
+				}, maxRetries, cfg.BaseDelay)
+
+				if err != nil {
+					failed[idx] = &DLQEntry[T]{Message: msg, Err: err, Attempts: attempts, FailedAt: time.Now()}
+				}
+			}
+		}()
+	}
+
+	next := 0
+dispatch:
+	for ; next < len(messages); next++ {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- next:
 		}
 	}
+	close(jobs)
+	wg.Wait()
+
+	for idx := next; idx < len(messages); idx++ {
+		failed[idx] = &DLQEntry[T]{Message: messages[idx], Err: ctx.Err(), FailedAt: time.Now()}
+	}
+
+	for _, entry := range failed {
+		if entry != nil {
+			dlq.AddEntries(*entry)
+		}
+	}
+
+	return ctx.Err()
 }
@@ -1,8 +1,11 @@
 package patterns_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -107,7 +110,7 @@ func TestTimeout(t *testing.T) {
 
 func TestDLQ(t *testing.T) {
 	t.Run("add messages to DLQ on failure", func(t *testing.T) {
-		dlq := patterns.NewDeadLetterQueue()
+		dlq := patterns.NewDeadLetterQueue[string]()
 		messages := []string{"msg1", "msg2", "msg3"}
 
 		patterns.ProcessWithDLQ(messages, func(msg string) error {
@@ -132,7 +135,7 @@ func TestDLQ(t *testing.T) {
 	})
 
 	t.Run("no messages in DLQ on success", func(t *testing.T) {
-		dlq := patterns.NewDeadLetterQueue()
+		dlq := patterns.NewDeadLetterQueue[string]()
 		messages := []string{"msg1", "msg2", "msg3"}
 
 		patterns.ProcessWithDLQ(messages, func(msg string) error {
@@ -145,7 +148,7 @@ func TestDLQ(t *testing.T) {
 	})
 
 	t.Run("clear DLQ", func(t *testing.T) {
-		dlq := patterns.NewDeadLetterQueue()
+		dlq := patterns.NewDeadLetterQueue[string]()
 		messages := []string{"msg1", "msg2"}
 
 		patterns.ProcessWithDLQ(messages, func(msg string) error {
@@ -168,7 +171,7 @@ func TestDLQ(t *testing.T) {
 	})
 
 	t.Run("thread safety", func(t *testing.T) {
-		dlq := patterns.NewDeadLetterQueue()
+		dlq := patterns.NewDeadLetterQueue[string]()
 		var wg sync.WaitGroup
 
 		for i := range 50 {
@@ -190,3 +193,292 @@ func TestDLQ(t *testing.T) {
 		}
 	})
 }
+
+func TestProcessConcurrently(t *testing.T) {
+	cfg := patterns.ProcessorCfg{
+		Workers:    4,
+		MaxRetries: 3,
+		BaseDelay:  10 * time.Millisecond,
+		Timeout:    100 * time.Millisecond,
+	}
+
+	t.Run("failed messages keep input order", func(t *testing.T) {
+		dlq := patterns.NewDeadLetterQueue[int]()
+		messages := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+		err := patterns.ProcessConcurrently(context.Background(), messages, func(_ context.Context, msg int) error {
+			if msg%2 == 0 {
+				time.Sleep(time.Duration(10-msg) * time.Millisecond)
+				return fmt.Errorf("even message %d", msg)
+			}
+			return nil
+		}, dlq, cfg)
+		if err != nil {
+			t.Fatalf("expected nil err, but got: %v", err)
+		}
+
+		got := dlq.GetMessages()
+		expected := []int{2, 4, 6, 8, 10}
+		if fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Fatalf("expected %v in DLQ, got %v", expected, got)
+		}
+
+		for _, entry := range dlq.GetEntries() {
+			if entry.Err == nil {
+				t.Errorf("expected error metadata for message %d", entry.Message)
+			}
+			if entry.Attempts != cfg.MaxRetries {
+				t.Errorf("expected %d attempts for message %d, got %d", cfg.MaxRetries, entry.Message, entry.Attempts)
+			}
+		}
+	})
+
+	t.Run("retry and timeout before dead-lettering", func(t *testing.T) {
+		dlq := patterns.NewDeadLetterQueue[string]()
+		var calls atomic.Int32
+
+		err := patterns.ProcessConcurrently(context.Background(), []string{"flaky", "slow"},
+			func(ctx context.Context, msg string) error {
+				if msg == "flaky" {
+					if calls.Add(1) < 2 {
+						return fmt.Errorf("temporary error")
+					}
+					return nil
+				}
+
+				select {
+				case <-time.After(time.Second):
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}, dlq, cfg)
+		if err != nil {
+			t.Fatalf("expected nil err, but got: %v", err)
+		}
+
+		entries := dlq.GetEntries()
+		if len(entries) != 1 || entries[0].Message != "slow" {
+			t.Fatalf("expected only 'slow' in DLQ, got %v", dlq.GetMessages())
+		}
+
+		if !errors.Is(entries[0].Err, patterns.ErrTimeoutExceeded) {
+			t.Errorf("expected timeout error, got %v", entries[0].Err)
+		}
+	})
+
+	t.Run("bounded concurrency", func(t *testing.T) {
+		dlq := patterns.NewDeadLetterQueue[int]()
+		var running, peak atomic.Int32
+
+		messages := make([]int, 20)
+		err := patterns.ProcessConcurrently(context.Background(), messages, func(context.Context, int) error {
+			cur := running.Add(1)
+			defer running.Add(-1)
+
+			for {
+				old := peak.Load()
+				if cur <= old || peak.CompareAndSwap(old, cur) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}, dlq, cfg)
+		if err != nil {
+			t.Fatalf("expected nil err, but got: %v", err)
+		}
+
+		if peak.Load() > int32(cfg.Workers) {
+			t.Errorf("expected at most %d concurrent operations, got %d", cfg.Workers, peak.Load())
+		}
+	})
+
+	t.Run("cancellation dead-letters pending messages", func(t *testing.T) {
+		dlq := patterns.NewDeadLetterQueue[int]()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		messages := make([]int, 100)
+		for i := range messages {
+			messages[i] = i
+		}
+
+		var processed atomic.Int32
+		err := patterns.ProcessConcurrently(ctx, messages, func(context.Context, int) error {
+			if processed.Add(1) == 10 {
+				cancel()
+			}
+			return nil
+		}, dlq, cfg)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+
+		if int(processed.Load())+dlq.Size() < len(messages) {
+			t.Fatalf("expected every unprocessed message to be dead-lettered, processed %d, DLQ %d",
+				processed.Load(), dlq.Size())
+		}
+
+		prev := -1
+		for _, entry := range dlq.GetEntries() {
+			if !errors.Is(entry.Err, context.Canceled) {
+				t.Errorf("expected context.Canceled for message %d, got %v", entry.Message, entry.Err)
+			}
+			if entry.Message <= prev {
+				t.Errorf("expected DLQ in input order, got %d after %d", entry.Message, prev)
+			}
+			prev = entry.Message
+		}
+	})
+}
+
+func TestBulkhead(t *testing.T) {
+	t.Run("limits concurrent operations", func(t *testing.T) {
+		bulkhead := patterns.NewBulkhead(2, 0)
+		release := make(chan struct{})
+		started := make(chan struct{}, 2)
+
+		var wg sync.WaitGroup
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = bulkhead.Execute(context.Background(), func() error {
+					started <- struct{}{}
+					<-release
+					return nil
+				})
+			}()
+		}
+		<-started
+		<-started
+
+		err := bulkhead.Execute(context.Background(), func() error { return nil })
+		if !errors.Is(err, patterns.ErrBulkheadFull) {
+			t.Fatalf("expected ErrBulkheadFull, got %v", err)
+		}
+
+		close(release)
+		wg.Wait()
+
+		if bulkhead.InUse() != 0 {
+			t.Errorf("expected all slots released, got %d in use", bulkhead.InUse())
+		}
+	})
+
+	t.Run("waits in queue until timeout", func(t *testing.T) {
+		queueTimeout := 100 * time.Millisecond
+		bulkhead := patterns.NewBulkhead(1, queueTimeout)
+		release := make(chan struct{})
+		started := make(chan struct{})
+
+		go func() {
+			_ = bulkhead.Execute(context.Background(), func() error {
+				close(started)
+				<-release
+				return nil
+			})
+		}()
+		<-started
+
+		start := time.Now()
+		err := bulkhead.Execute(context.Background(), func() error { return nil })
+		if !errors.Is(err, patterns.ErrBulkheadFull) {
+			t.Fatalf("expected ErrBulkheadFull, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < queueTimeout {
+			t.Fatalf("expected to wait at least %v, waited %v", queueTimeout, elapsed)
+		}
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			close(release)
+		}()
+
+		err = bulkhead.Execute(context.Background(), func() error { return nil })
+		if err != nil {
+			t.Fatalf("expected queued operation to run, got %v", err)
+		}
+	})
+
+	t.Run("classes have separate budgets", func(t *testing.T) {
+		group := patterns.NewBulkheadGroup(map[string]patterns.BulkheadLimit{
+			"list":  {MaxConcurrent: 1},
+			"point": {MaxConcurrent: 1},
+		})
+		release := make(chan struct{})
+		started := make(chan struct{})
+
+		go func() {
+			_ = group.Execute(context.Background(), "list", func() error {
+				close(started)
+				<-release
+				return nil
+			})
+		}()
+		<-started
+		defer close(release)
+
+		if err := group.Execute(context.Background(), "list", func() error { return nil }); !errors.Is(
+			err, patterns.ErrBulkheadFull) {
+			t.Fatalf("expected list class to be full, got %v", err)
+		}
+
+		if err := group.Execute(context.Background(), "point", func() error { return nil }); err != nil {
+			t.Fatalf("expected point class to be available, got %v", err)
+		}
+	})
+}
+
+func TestTokenBucketLimiter(t *testing.T) {
+	t.Run("allows burst then rejects", func(t *testing.T) {
+		limiter := patterns.NewTokenBucketLimiter(1, 3)
+
+		for i := range 3 {
+			allowed, _, err := limiter.Allow(context.Background(), "client")
+			if err != nil || !allowed {
+				t.Fatalf("expected request %d to be allowed, got allowed=%v err=%v", i, allowed, err)
+			}
+		}
+
+		allowed, retryAfter, err := limiter.Allow(context.Background(), "client")
+		if err != nil {
+			t.Fatalf("expected nil err, but got: %v", err)
+		}
+		if allowed {
+			t.Fatalf("expected request over burst to be rejected")
+		}
+		if retryAfter <= 0 || retryAfter > time.Second {
+			t.Errorf("expected retry after in (0, 1s], got %v", retryAfter)
+		}
+	})
+
+	t.Run("keys have separate buckets", func(t *testing.T) {
+		limiter := patterns.NewTokenBucketLimiter(1, 1)
+
+		if allowed, _, _ := limiter.Allow(context.Background(), "a"); !allowed {
+			t.Fatalf("expected first request for 'a' to be allowed")
+		}
+		if allowed, _, _ := limiter.Allow(context.Background(), "a"); allowed {
+			t.Fatalf("expected second request for 'a' to be rejected")
+		}
+		if allowed, _, _ := limiter.Allow(context.Background(), "b"); !allowed {
+			t.Fatalf("expected first request for 'b' to be allowed")
+		}
+	})
+
+	t.Run("refills over time", func(t *testing.T) {
+		limiter := patterns.NewTokenBucketLimiter(20, 1)
+
+		if allowed, _, _ := limiter.Allow(context.Background(), "client"); !allowed {
+			t.Fatalf("expected first request to be allowed")
+		}
+
+		time.Sleep(60 * time.Millisecond)
+
+		if allowed, _, _ := limiter.Allow(context.Background(), "client"); !allowed {
+			t.Fatalf("expected request after refill to be allowed")
+		}
+	})
+}
@@ -1,10 +1,15 @@
 package patterns
 
 import (
+	"context"
 	"time"
 )
 
 func Retry(operation func() error, maxRetries int, baseDelay time.Duration) error {
+	return RetryContext(context.Background(), operation, maxRetries, baseDelay)
+}
+
+func RetryContext(ctx context.Context, operation func() error, maxRetries int, baseDelay time.Duration) error {
 	var err error
 
 	for attempt := range maxRetries {
@@ -14,7 +19,14 @@ func Retry(operation func() error, maxRetries int, baseDelay time.Duration) erro
 
 		if attempt < maxRetries-1 {
 			delay := baseDelay * (1 << attempt)
-			time.Sleep(delay)
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
 		}
 	}
 
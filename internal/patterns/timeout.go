@@ -2,17 +2,27 @@ package patterns
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"time"
 )
 
+var (
+	ErrTimeoutExceeded = errors.New("waiting time exceeded")
+)
+
 func Timeout(operation func() error, timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	return TimeoutContext(context.Background(), func(context.Context) error {
+		return operation()
+	}, timeout)
+}
+
+func TimeoutContext(ctx context.Context, operation func(ctx context.Context) error, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	errChan := make(chan error)
+	errChan := make(chan error, 1)
 	go func() {
-		errChan <- operation()
+		errChan <- operation(ctx)
 	}()
 
 	select {
@@ -20,6 +30,9 @@ func Timeout(operation func() error, timeout time.Duration) error {
 		return err
 
 	case <-ctx.Done():
-		return fmt.Errorf("waiting time exceeded")
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ErrTimeoutExceeded
+		}
+		return ctx.Err()
 	}
 }
@@ -9,27 +9,36 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/metrics"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tenant"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tracing"
 	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+const tracerName = "order-service/cache"
+
 type RedisCfg struct {
 	Host     string `env:"REDIS_HOST"     env-default:"redis"`
 	Port     string `env:"REDIS_PORT"     env-default:"6379"`
-	Password string `env:"REDIS_PASSWORD" env-default:"redis"`
+	Password string `env:"REDIS_PASSWORD" env-default:"redis" secret:"true"`
 }
 
 type OrdersCache struct {
 	redisClient *redis.Client
 	wg          sync.WaitGroup
+	metrics     *metrics.Metrics
 }
 
 var (
 	ErrOrderNotFound = errors.New("order not found in cache")
 )
 
-func NewOrdersCache(ctx context.Context, cfg RedisCfg) (*OrdersCache, error) {
+func NewOrdersCache(ctx context.Context, cfg RedisCfg, m *metrics.Metrics) (*OrdersCache, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
 		Password: cfg.Password,
@@ -42,6 +51,7 @@ func NewOrdersCache(ctx context.Context, cfg RedisCfg) (*OrdersCache, error) {
 	return &OrdersCache{
 		redisClient: client,
 		wg:          sync.WaitGroup{},
+		metrics:     m,
 	}, nil
 }
 
@@ -49,27 +59,40 @@ func (c *OrdersCache) Wait() {
 	c.wg.Wait()
 }
 
+func (c *OrdersCache) Ping(ctx context.Context) error {
+	return c.redisClient.Ping(ctx).Err()
+}
+
 func (c *OrdersCache) Close(ctx context.Context) {
 	if c.redisClient != nil {
 		if err := c.redisClient.Close(); err != nil {
-			logger.GetLoggerFromCtx(ctx).Error(ctx, "error closing Redis connection", zap.Error(err))
+			logger.GetLoggerFromCtx(ctx).Named("cache").Error(ctx, "error closing Redis connection", zap.Error(err))
 		}
 	}
 }
 
 func (c *OrdersCache) SetOrder(ctx context.Context, order *api.Order) {
+	redisKey, err := orderKey(ctx, order.GetId())
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Named("cache").Error(ctx, "failed to set order to redis", zap.Error(err))
+		return
+	}
+
 	c.wg.Add(1)
+	link := trace.LinkFromContext(ctx)
 
 	go func() {
 		defer c.wg.Done()
 
-		bgCtx := context.Background()
-		log := logger.GetLoggerFromCtx(ctx)
+		bgCtx, span := startSpan(context.Background(), "SetOrder", redisKey, trace.WithLinks(link))
+		var err error
+		defer func() { tracing.End(span, err) }()
+
+		log := logger.GetLoggerFromCtx(ctx).Named("cache")
 
 		log.Debug(ctx, "SetOrder - marshaling order",
 			zap.String("id", order.GetId()),
-			zap.String("item", order.GetItem()),
-			zap.Int32("quantity", order.GetQuantity()),
+			log.Payload("order", order),
 		)
 
 		data, err := json.Marshal(order)
@@ -78,39 +101,49 @@ func (c *OrdersCache) SetOrder(ctx context.Context, order *api.Order) {
 			return
 		}
 
-		log.Debug(ctx, "SetOrder - JSON data",
-			zap.String("json", string(data)),
-			zap.String("redis_key", order.GetId()),
-		)
-
 		const defaultTTL = time.Minute * 30
-		err = c.redisClient.Set(bgCtx, order.GetId(), data, defaultTTL).Err()
+		err = c.redisClient.Set(bgCtx, redisKey, data, defaultTTL).Err()
 		if err != nil {
+			c.metrics.ObserveCache("set", metrics.CacheError)
 			log.Error(ctx, "failed to set order to redis", zap.Error(err), zap.String("id", order.GetId()))
 			return
 		}
+		c.metrics.ObserveCache("set", metrics.CacheOK)
 
 		log.Debug(ctx, "order successfully set to redis", zap.String("id", order.GetId()))
 	}()
 }
 
-func (c *OrdersCache) GetOrder(ctx context.Context, id string) (*api.Order, error) {
-	log := logger.GetLoggerFromCtx(ctx)
+func (c *OrdersCache) GetOrder(ctx context.Context, id string) (_ *api.Order, err error) {
+	log := logger.GetLoggerFromCtx(ctx).Named("cache")
+
+	redisKey, err := orderKey(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error with cache: %w", err)
+	}
+
+	ctx, span := startSpan(ctx, "GetOrder", redisKey)
+	defer func() {
+		span.SetAttributes(attribute.Bool("cache.hit", err == nil))
+		if errors.Is(err, ErrOrderNotFound) {
+			tracing.End(span, nil)
+			return
+		}
+		tracing.End(span, err)
+	}()
 
-	log.Debug(ctx, "GetOrder - searching in Redis", zap.String("redis_key", id))
+	log.Debug(ctx, "GetOrder - searching in Redis", zap.String("redis_key", redisKey))
 
-	val, err := c.redisClient.Get(ctx, id).Bytes()
+	val, err := c.redisClient.Get(ctx, redisKey).Bytes()
 	if errors.Is(err, redis.Nil) {
-		log.Debug(ctx, "GetOrder - not found in Redis", zap.String("redis_key", id))
+		c.metrics.ObserveCache("get", metrics.CacheMiss)
+		log.Debug(ctx, "GetOrder - not found in Redis", zap.String("redis_key", redisKey))
 		return nil, ErrOrderNotFound
 	} else if err != nil {
+		c.metrics.ObserveCache("get", metrics.CacheError)
 		return nil, fmt.Errorf("error with cache: %w", err)
 	}
-
-	log.Debug(ctx, "GetOrder - raw data from Redis",
-		zap.String("redis_key", id),
-		zap.String("raw_json", string(val)),
-	)
+	c.metrics.ObserveCache("get", metrics.CacheHit)
 
 	var order api.Order
 	if err = json.Unmarshal(val, &order); err != nil {
@@ -118,28 +151,67 @@ func (c *OrdersCache) GetOrder(ctx context.Context, id string) (*api.Order, erro
 	}
 
 	log.Debug(ctx, "GetOrder - unmarshaled order",
-		zap.String("id", order.GetId()),
-		zap.String("item", order.GetItem()),
-		zap.Int32("quantity", order.GetQuantity()),
+		zap.String("redis_key", redisKey),
+		log.Payload("order", &order),
 	)
 
 	return &order, nil
 }
 
 func (c *OrdersCache) DeleteOrder(ctx context.Context, id string) {
+	redisKey, err := orderKey(ctx, id)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Named("cache").Error(ctx, "failed to delete order from redis", zap.Error(err))
+		return
+	}
+
 	c.wg.Add(1)
+	link := trace.LinkFromContext(ctx)
 
 	go func() {
 		defer c.wg.Done()
-		bgCtx := context.Background()
 
-		log := logger.GetLoggerFromCtx(ctx)
+		bgCtx, span := startSpan(context.Background(), "DeleteOrder", redisKey, trace.WithLinks(link))
+		var err error
+		defer func() { tracing.End(span, err) }()
 
-		err := c.redisClient.Del(bgCtx, id).Err()
+		log := logger.GetLoggerFromCtx(ctx).Named("cache")
+
+		err = c.redisClient.Del(bgCtx, redisKey).Err()
 		if err != nil {
+			c.metrics.ObserveCache("delete", metrics.CacheError)
 			log.Error(ctx, "failed to delete order from redis", zap.Error(err), zap.String("id", id))
+			return
 		}
+		c.metrics.ObserveCache("delete", metrics.CacheOK)
 
 		log.Debug(ctx, "successfully deleted order from redis", zap.String("id", id))
 	}()
 }
+
+func startSpan(
+	ctx context.Context,
+	operation string,
+	redisKey string,
+	opts ...trace.SpanStartOption,
+) (context.Context, trace.Span) {
+	opts = append(opts,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation", operation),
+			attribute.String("cache.key", redisKey),
+		),
+	)
+
+	return otel.Tracer(tracerName).Start(ctx, "OrdersCache."+operation, opts...)
+}
+
+func orderKey(ctx context.Context, id string) (string, error) {
+	tenantID, err := tenant.FromCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return "orders:" + tenantID + ":" + id, nil
+}
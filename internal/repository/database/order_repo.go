@@ -5,19 +5,47 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tenant"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tracing"
 	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type PostgresCfg struct {
-	Host     string `env:"POSTGRES_HOST"     env-default:"postgres"`
-	Port     string `env:"POSTGRES_PORT"     env-default:"5432"`
-	User     string `env:"POSTGRES_USER"     env-default:"postgres"`
-	Password string `env:"POSTGRES_PASSWORD" env-default:"postgres"`
-	DBName   string `env:"POSTGRES_DB"       env-default:"postgres"`
+	Host        string `env:"POSTGRES_HOST"        env-default:"postgres"`
+	Port        string `env:"POSTGRES_PORT"        env-default:"5432"`
+	User        string `env:"POSTGRES_USER"        env-default:"postgres"`
+	Password    string `env:"POSTGRES_PASSWORD"    env-default:"postgres" secret:"true"`
+	DBName      string `env:"POSTGRES_DB"          env-default:"postgres"`
+	SSLMode     string `env:"POSTGRES_SSLMODE"     env-default:"disable"`
+	SSLRootCert string `env:"POSTGRES_SSLROOTCERT"`
+	SSLCert     string `env:"POSTGRES_SSLCERT"`
+	SSLKey      string `env:"POSTGRES_SSLKEY"`
+}
+
+const tracerName = "order-service/database"
+
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ErrNotFound is returned when no order with the id is visible to the
+// caller's tenant and customer scope.
+var ErrNotFound = errors.New("order not found")
+
+type TenantOrder struct {
+	TenantID string
+	Order    *api.Order
 }
 
 type OrdersDB struct {
@@ -26,11 +54,7 @@ type OrdersDB struct {
 }
 
 func NewOrderDB(ctx context.Context, cfg PostgresCfg) (*OrdersDB, error) {
-	addr := net.JoinHostPort(cfg.Host, cfg.Port)
-	dataSource := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable",
-		cfg.User, cfg.Password, addr, cfg.DBName)
-
-	pool, err := pgxpool.New(ctx, dataSource)
+	pool, err := pgxpool.New(ctx, cfg.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new pool: %w", err)
 	}
@@ -46,48 +70,131 @@ func NewOrderDB(ctx context.Context, cfg PostgresCfg) (*OrdersDB, error) {
 	}, nil
 }
 
+func (cfg PostgresCfg) DSN() string {
+	query := url.Values{}
+	query.Set("sslmode", cfg.SSLMode)
+	for param, value := range map[string]string{
+		"sslrootcert": cfg.SSLRootCert,
+		"sslcert":     cfg.SSLCert,
+		"sslkey":      cfg.SSLKey,
+	} {
+		if value != "" {
+			query.Set(param, value)
+		}
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Path:     "/" + cfg.DBName,
+		RawQuery: query.Encode(),
+	}
+
+	return dsn.String()
+}
+
+func (d *OrdersDB) Stat() *pgxpool.Stat {
+	return d.db.Stat()
+}
+
+func (d *OrdersDB) Ping(ctx context.Context) error {
+	return d.db.Ping(ctx)
+}
+
 func (d *OrdersDB) Close() {
 	if d.db != nil {
 		d.db.Close()
 	}
 }
 
-func (d *OrdersDB) InsertOrder(ctx context.Context, item string, quantity int32) (string, error) {
-	query, args, err := d.builder.Insert("orders").
-		Columns("item", "quantity").
-		Values(item, quantity).
-		Suffix("RETURNING id").
-		ToSql()
-
+// BypassesRowLevelSecurity reports whether the connected role is a superuser
+// or has BYPASSRLS, in which case the tenant_isolation policy is not applied
+// and tenant scoping relies on the tenant_id conditions alone.
+func (d *OrdersDB) BypassesRowLevelSecurity(ctx context.Context) (bool, error) {
+	var bypass bool
+	err := d.db.QueryRow(ctx,
+		"SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user",
+	).Scan(&bypass)
 	if err != nil {
-		return "", fmt.Errorf("insert: %w", err)
+		return false, fmt.Errorf("check role attributes: %w", err)
 	}
 
-	var id string
-	err = d.db.QueryRow(ctx, query, args...).Scan(&id)
+	return bypass, nil
+}
+
+// withTenant resolves the tenant from ctx and runs fn with it inside a
+// transaction that sets app.tenant_id, which the tenant_isolation policy on
+// the orders table checks.
+func (d *OrdersDB) withTenant(
+	ctx context.Context,
+	operation string,
+	fn func(q querier, tenantID string) error,
+) (err error) {
+	ctx, span := startSpan(ctx, operation)
+	defer func() { tracing.End(span, err) }()
+
+	tenantID, err := tenant.FromCtx(ctx)
 	if err != nil {
-		return "", fmt.Errorf("insert: %w", err)
+		return err
 	}
+	span.SetAttributes(attribute.String("tenant.id", tenantID))
 
-	return id, nil
+	return inTenant(ctx, d.db, tenantID, func(tx pgx.Tx) error {
+		return fn(tx, tenantID)
+	})
 }
 
-func (d *OrdersDB) SelectOrder(ctx context.Context, id string) (*api.Order, error) {
-	query, args, err := d.builder.Select(
-		"id", "item", "quantity").
-		From("orders").
-		Where(squirrel.Eq{"id": id}).
-		ToSql()
+func inTenant(ctx context.Context, db *pgxpool.Pool, tenantID string, fn func(tx pgx.Tx) error) error {
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantID); err != nil {
+			return fmt.Errorf("set tenant: %w", err)
+		}
+
+		return fn(tx)
+	})
+}
 
+func (d *OrdersDB) InsertOrder(ctx context.Context, customerID string, item string, quantity int32) (string, error) {
+	var id string
+	err := d.withTenant(ctx, "InsertOrder", func(q querier, tenantID string) error {
+		query, args, err := d.builder.Insert("orders").
+			Columns("item", "quantity", "customer_id", "tenant_id").
+			Values(item, quantity, customerID, tenantID).
+			Suffix("RETURNING id").
+			ToSql()
+
+		if err != nil {
+			return err
+		}
+
+		return q.QueryRow(ctx, query, args...).Scan(&id)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("select: %w", err)
+		return "", fmt.Errorf("insert: %w", err)
 	}
 
+	return id, nil
+}
+
+func (d *OrdersDB) SelectOrder(ctx context.Context, id string, customerID string) (*api.Order, error) {
 	order := api.Order{}
-	err = d.db.QueryRow(ctx, query, args...).Scan(&order.Id, &order.Item, &order.Quantity)
+	err := d.withTenant(ctx, "SelectOrder", func(q querier, tenantID string) error {
+		query, args, err := d.builder.Select(
+			"id", "item", "quantity", "customer_id").
+			From("orders").
+			Where(scoped(squirrel.Eq{"id": id}, tenantID, customerID)).
+			ToSql()
+
+		if err != nil {
+			return err
+		}
+
+		return q.QueryRow(ctx, query, args...).Scan(&order.Id, &order.Item, &order.Quantity, &order.CustomerId)
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("select: order with id %s does not exists", id)
+			return nil, fmt.Errorf("select order %s: %w", id, ErrNotFound)
 		}
 		return nil, fmt.Errorf("select: %w", err)
 	}
@@ -95,23 +202,31 @@ func (d *OrdersDB) SelectOrder(ctx context.Context, id string) (*api.Order, erro
 	return &order, nil
 }
 
-func (d *OrdersDB) UpdateOrder(ctx context.Context, id string, item string, quantity int32) (*api.Order, error) {
-	query, args, err := d.builder.Update("orders").
-		Set("item", item).
-		Set("quantity", quantity).
-		Where(squirrel.Eq{"id": id}).
-		Suffix("RETURNING id, item, quantity").
-		ToSql()
-
-	if err != nil {
-		return nil, fmt.Errorf("update: %w", err)
-	}
-
+func (d *OrdersDB) UpdateOrder(
+	ctx context.Context,
+	id string,
+	customerID string,
+	item string,
+	quantity int32,
+) (*api.Order, error) {
 	order := &api.Order{}
-	err = d.db.QueryRow(ctx, query, args...).Scan(&order.Id, &order.Item, &order.Quantity)
+	err := d.withTenant(ctx, "UpdateOrder", func(q querier, tenantID string) error {
+		query, args, err := d.builder.Update("orders").
+			Set("item", item).
+			Set("quantity", quantity).
+			Where(scoped(squirrel.Eq{"id": id}, tenantID, customerID)).
+			Suffix("RETURNING id, item, quantity, customer_id").
+			ToSql()
+
+		if err != nil {
+			return err
+		}
+
+		return q.QueryRow(ctx, query, args...).Scan(&order.Id, &order.Item, &order.Quantity, &order.CustomerId)
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("select: order with id %s does not exists", id)
+			return nil, fmt.Errorf("update order %s: %w", id, ErrNotFound)
 		}
 		return nil, fmt.Errorf("update: %w", err)
 	}
@@ -119,74 +234,135 @@ func (d *OrdersDB) UpdateOrder(ctx context.Context, id string, item string, quan
 	return order, nil
 }
 
-func (d *OrdersDB) DeleteOrder(ctx context.Context, id string) (bool, error) {
-	query, args, err := d.builder.Delete("orders").
-		Where(squirrel.Eq{"id": id}).
-		ToSql()
+func (d *OrdersDB) DeleteOrder(ctx context.Context, id string, customerID string) (bool, error) {
+	var rowsAffected int64
+	err := d.withTenant(ctx, "DeleteOrder", func(q querier, tenantID string) error {
+		query, args, err := d.builder.Delete("orders").
+			Where(scoped(squirrel.Eq{"id": id}, tenantID, customerID)).
+			ToSql()
 
-	if err != nil {
-		return false, fmt.Errorf("delete: %w", err)
-	}
+		if err != nil {
+			return err
+		}
 
-	res, err := d.db.Exec(ctx, query, args...)
+		res, err := q.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected = res.RowsAffected()
+		return nil
+	})
 	if err != nil {
 		return false, fmt.Errorf("delete: %w", err)
 	}
 
-	rowsAffected := res.RowsAffected()
 	if rowsAffected == 0 {
-		return false, fmt.Errorf("delete: order with id %s does not exists", id)
+		return false, fmt.Errorf("delete order %s: %w", id, ErrNotFound)
 	}
 
 	return true, nil
 }
 
-func (d *OrdersDB) SelectOrdersList(ctx context.Context) ([]*api.Order, error) {
-	query, args, err := d.builder.Select(
-		"id", "item", "quantity").
-		From("orders").
-		ToSql()
+func (d *OrdersDB) SelectOrdersList(ctx context.Context, customerID string) ([]*api.Order, error) {
+	orders := make([]*api.Order, 0)
+	err := d.withTenant(ctx, "SelectOrdersList", func(q querier, tenantID string) error {
+		query, args, err := d.builder.Select(
+			"id", "item", "quantity", "customer_id").
+			From("orders").
+			Where(scoped(squirrel.Eq{}, tenantID, customerID)).
+			ToSql()
+
+		if err != nil {
+			return err
+		}
 
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			order := &api.Order{}
+			if err = rows.Scan(&order.Id, &order.Item, &order.Quantity, &order.CustomerId); err != nil {
+				return err
+			}
+
+			orders = append(orders, order)
+		}
+
+		return rows.Err()
+	})
 	if err != nil {
 		return nil, fmt.Errorf("select: %w", err)
 	}
 
-	rows, err := d.db.Query(ctx, query, args...)
+	return orders, nil
+}
+
+// SelectOrdersForCache reads across all tenants and therefore bypasses
+// withTenant; every returned order carries the tenant it belongs to. The
+// tenant_isolation policy hides cross-tenant reads, so the tenants are listed
+// through the order_tenants SECURITY DEFINER function and read one by one
+// with app.tenant_id set.
+func (d *OrdersDB) SelectOrdersForCache(ctx context.Context, limit uint64) (_ []TenantOrder, err error) {
+	ctx, span := startSpan(ctx, "SelectOrdersForCache")
+	defer func() { tracing.End(span, err) }()
+
+	tenants, err := d.selectTenants(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("select: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	orders := make([]*api.Order, 0)
-	for rows.Next() {
-		order := &api.Order{}
-		if err = rows.Scan(&order.Id, &order.Item, &order.Quantity); err != nil {
-			return nil, fmt.Errorf("select: %w", err)
+	var orders []TenantOrder
+	for _, tenantID := range tenants {
+		if uint64(len(orders)) >= limit {
+			break
 		}
 
-		orders = append(orders, order)
-	}
+		err = inTenant(ctx, d.db, tenantID, func(tx pgx.Tx) error {
+			tenantOrders, err := selectOrdersForCache(ctx, tx, limit-uint64(len(orders)))
+			orders = append(orders, tenantOrders...)
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("select: %w", err)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return orders, nil
 }
 
-func (d *OrdersDB) SelectOrdersForCache(ctx context.Context, limit uint64) ([]*api.Order, error) {
-	query := "SELECT id, item, quantity FROM orders LIMIT $1"
+func (d *OrdersDB) selectTenants(ctx context.Context) ([]string, error) {
+	rows, err := d.db.Query(ctx, "SELECT order_tenants()")
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tenants: %w", err)
+	}
 
-	rows, err := d.db.Query(ctx, query, limit)
+	tenants, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tenants: %w", err)
+	}
+
+	return tenants, nil
+}
+
+func selectOrdersForCache(ctx context.Context, q querier, limit uint64) ([]TenantOrder, error) {
+	query := "SELECT id, item, quantity, customer_id, tenant_id FROM orders LIMIT $1"
+
+	rows, err := q.Query(ctx, query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to select orders: %w", err)
 	}
 	defer rows.Close()
 
-	var orders []*api.Order
+	var orders []TenantOrder
 	for rows.Next() {
-		order := &api.Order{}
-		if err = rows.Scan(&order.Id, &order.Item, &order.Quantity); err != nil {
+		order := TenantOrder{Order: &api.Order{}}
+		if err = rows.Scan(&order.Order.Id, &order.Order.Item, &order.Order.Quantity,
+			&order.Order.CustomerId, &order.TenantID); err != nil {
 			return nil, err
 		}
 		orders = append(orders, order)
@@ -194,3 +370,22 @@ func (d *OrdersDB) SelectOrdersForCache(ctx context.Context, limit uint64) ([]*a
 
 	return orders, rows.Err()
 }
+
+func startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "OrdersDB."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", operation),
+		),
+	)
+}
+
+func scoped(where squirrel.Eq, tenantID string, customerID string) squirrel.Eq {
+	where["tenant_id"] = tenantID
+	if customerID != "" {
+		where["customer_id"] = customerID
+	}
+
+	return where
+}
@@ -4,38 +4,71 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/metrics"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/patterns"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/repository/cache"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/repository/database"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tenant"
 	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
 	"go.uber.org/zap"
 )
 
+const (
+	pointQueries = "point"
+	listQueries  = "list"
+)
+
+type BulkheadCfg struct {
+	PointLimit   int           `env:"BULKHEAD_POINT_LIMIT"   env-default:"16"`
+	ListLimit    int           `env:"BULKHEAD_LIST_LIMIT"    env-default:"2"`
+	QueueTimeout time.Duration `env:"BULKHEAD_QUEUE_TIMEOUT" env-default:"500ms"`
+}
+
 type OrderRepository struct {
-	db    *database.OrdersDB
-	cache *cache.OrdersCache
+	db        *database.OrdersDB
+	cache     *cache.OrdersCache
+	bulkheads *patterns.BulkheadGroup
+	metrics   *metrics.Metrics
 }
 
-func NewOrderRepository(db *database.OrdersDB, cache *cache.OrdersCache) *OrderRepository {
+func NewOrderRepository(
+	db *database.OrdersDB,
+	cache *cache.OrdersCache,
+	cfg BulkheadCfg,
+	m *metrics.Metrics,
+) *OrderRepository {
 	return &OrderRepository{
-		db:    db,
-		cache: cache,
+		db:      db,
+		cache:   cache,
+		metrics: m,
+		bulkheads: patterns.NewBulkheadGroup(map[string]patterns.BulkheadLimit{
+			pointQueries: {MaxConcurrent: cfg.PointLimit, QueueTimeout: cfg.QueueTimeout},
+			listQueries:  {MaxConcurrent: cfg.ListLimit, QueueTimeout: cfg.QueueTimeout},
+		}),
 	}
 }
 
 func (r *OrderRepository) WarmUpCache(ctx context.Context, limit uint64) {
-	log := logger.GetLoggerFromCtx(ctx)
-
-	orders, err := r.db.SelectOrdersForCache(ctx, limit)
+	log := logger.GetLoggerFromCtx(ctx).Named("repository")
+
+	var orders []database.TenantOrder
+	err := r.bulkheads.Execute(ctx, listQueries, func() error {
+		var err error
+		orders, err = r.db.SelectOrdersForCache(ctx, limit)
+		return err
+	})
 	if err != nil {
 		log.Error(ctx, "failed to warm up cache", zap.Error(err))
 		return
 	}
 
 	for _, order := range orders {
-		r.cache.SetOrder(ctx, order)
+		r.cache.SetOrder(tenant.WithTenant(ctx, order.TenantID), order.Order)
 	}
+	r.metrics.SetCacheWarmUp(len(orders))
 
 	log.Info(ctx, "cache warm up completed",
 		zap.Int("orders_cached", len(orders)),
@@ -43,34 +76,45 @@ func (r *OrderRepository) WarmUpCache(ctx context.Context, limit uint64) {
 	)
 }
 
-func (r *OrderRepository) InsertOrder(ctx context.Context, item string, quantity int32) (string, error) {
-	id, err := r.db.InsertOrder(ctx, item, quantity)
+func (r *OrderRepository) InsertOrder(ctx context.Context, customerID string, item string, quantity int32) (string, error) {
+	var id string
+	err := r.bulkheads.Execute(ctx, pointQueries, func() error {
+		var err error
+		id, err = r.db.InsertOrder(ctx, customerID, item, quantity)
+		return err
+	})
 	if err != nil {
 		return "", fmt.Errorf("database: %w", err)
 	}
+	r.metrics.OrderCreated()
 
 	order := &api.Order{
-		Id:       id,
-		Item:     item,
-		Quantity: quantity,
+		Id:         id,
+		Item:       item,
+		Quantity:   quantity,
+		CustomerId: customerID,
 	}
 	go r.cache.SetOrder(ctx, order)
 
 	return id, nil
 }
 
-func (r *OrderRepository) SelectOrder(ctx context.Context, id string) (*api.Order, error) {
-	log := logger.GetLoggerFromCtx(ctx)
+func (r *OrderRepository) SelectOrder(ctx context.Context, id string, customerID string) (*api.Order, error) {
+	log := logger.GetLoggerFromCtx(ctx).Named("repository")
 
 	order, err := r.cache.GetOrder(ctx, id)
-	if err == nil && order != nil {
+	if err == nil && order != nil && (customerID == "" || order.GetCustomerId() == customerID) {
 		return order, nil
 	}
-	if !errors.Is(err, cache.ErrOrderNotFound) {
+	if err != nil && !errors.Is(err, cache.ErrOrderNotFound) {
 		log.Error(ctx, "cache error", zap.Error(err))
 	}
 
-	order, err = r.db.SelectOrder(ctx, id)
+	err = r.bulkheads.Execute(ctx, pointQueries, func() error {
+		var err error
+		order, err = r.db.SelectOrder(ctx, id, customerID)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("database: %w", err)
 	}
@@ -79,8 +123,19 @@ func (r *OrderRepository) SelectOrder(ctx context.Context, id string) (*api.Orde
 	return order, nil
 }
 
-func (r *OrderRepository) UpdateOrder(ctx context.Context, id string, item string, quantity int32) (*api.Order, error) {
-	order, err := r.db.UpdateOrder(ctx, id, item, quantity)
+func (r *OrderRepository) UpdateOrder(
+	ctx context.Context,
+	id string,
+	customerID string,
+	item string,
+	quantity int32,
+) (*api.Order, error) {
+	var order *api.Order
+	err := r.bulkheads.Execute(ctx, pointQueries, func() error {
+		var err error
+		order, err = r.db.UpdateOrder(ctx, id, customerID, item, quantity)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("database: %w", err)
 	}
@@ -90,19 +145,35 @@ func (r *OrderRepository) UpdateOrder(ctx context.Context, id string, item strin
 	return order, nil
 }
 
-func (r *OrderRepository) DeleteOrder(ctx context.Context, id string) (bool, error) {
-	success, err := r.db.DeleteOrder(ctx, id)
+func (r *OrderRepository) DeleteOrder(ctx context.Context, id string, customerID string) (bool, error) {
+	var success bool
+	err := r.bulkheads.Execute(ctx, pointQueries, func() error {
+		var err error
+		success, err = r.db.DeleteOrder(ctx, id, customerID)
+		return err
+	})
 	if err != nil {
 		return success, fmt.Errorf("database: %w", err)
 	}
+	if !success {
+		return false, nil
+	}
+	// counted next to OrderCreated, once the database confirmed the row is
+	// gone and independently of the cache invalidation below
+	r.metrics.OrderDeleted()
 
 	r.cache.DeleteOrder(ctx, id)
 
-	return success, nil
+	return true, nil
 }
 
-func (r *OrderRepository) ListOrders(ctx context.Context) ([]*api.Order, error) {
-	orders, err := r.db.SelectOrdersList(ctx)
+func (r *OrderRepository) ListOrders(ctx context.Context, customerID string) ([]*api.Order, error) {
+	var orders []*api.Order
+	err := r.bulkheads.Execute(ctx, listQueries, func() error {
+		var err error
+		orders, err = r.db.SelectOrdersList(ctx, customerID)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("database: %w", err)
 	}
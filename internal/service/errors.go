@@ -0,0 +1,12 @@
+package service
+
+// ValidationError reports a request field that failed validation, the
+// transport layer turns it into INVALID_ARGUMENT with a field violation.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
@@ -2,17 +2,17 @@ package service
 
 import (
 	"context"
-	"errors"
 
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/auth"
 	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
 )
 
 type OrderRepository interface {
-	InsertOrder(ctx context.Context, item string, quantity int32) (string, error)
-	SelectOrder(ctx context.Context, id string) (*api.Order, error)
-	UpdateOrder(ctx context.Context, id string, item string, quantity int32) (*api.Order, error)
-	DeleteOrder(ctx context.Context, id string) (bool, error)
-	ListOrders(ctx context.Context) ([]*api.Order, error)
+	InsertOrder(ctx context.Context, customerID string, item string, quantity int32) (string, error)
+	SelectOrder(ctx context.Context, id string, customerID string) (*api.Order, error)
+	UpdateOrder(ctx context.Context, id string, customerID string, item string, quantity int32) (*api.Order, error)
+	DeleteOrder(ctx context.Context, id string, customerID string) (bool, error)
+	ListOrders(ctx context.Context, customerID string) ([]*api.Order, error)
 }
 
 type OrderService struct {
@@ -27,14 +27,14 @@ func NewOrderService(repo OrderRepository) *OrderService {
 
 func (s *OrderService) CreateOrder(ctx context.Context, item string, quantity int32) (string, error) {
 	if item == "" {
-		return "", errors.New("item cannot be empty")
+		return "", &ValidationError{Field: "item", Reason: "item cannot be empty"}
 	}
 
 	if quantity <= 0 {
-		return "", errors.New("quantity must be positive")
+		return "", &ValidationError{Field: "quantity", Reason: "quantity must be positive"}
 	}
 
-	id, err := s.repository.InsertOrder(ctx, item, quantity)
+	id, err := s.repository.InsertOrder(ctx, customerID(ctx), item, quantity)
 	if err != nil {
 		return "", err
 	}
@@ -43,7 +43,7 @@ func (s *OrderService) CreateOrder(ctx context.Context, item string, quantity in
 }
 
 func (s *OrderService) GetOrder(ctx context.Context, id string) (*api.Order, error) {
-	order, err := s.repository.SelectOrder(ctx, id)
+	order, err := s.repository.SelectOrder(ctx, id, ownerFilter(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -53,14 +53,14 @@ func (s *OrderService) GetOrder(ctx context.Context, id string) (*api.Order, err
 
 func (s *OrderService) UpdateOrder(ctx context.Context, id string, item string, quantity int32) (*api.Order, error) {
 	if item == "" {
-		return nil, errors.New("item cannot be empty")
+		return nil, &ValidationError{Field: "item", Reason: "item cannot be empty"}
 	}
 
 	if quantity <= 0 {
-		return nil, errors.New("quantity must be positive")
+		return nil, &ValidationError{Field: "quantity", Reason: "quantity must be positive"}
 	}
 
-	order, err := s.repository.UpdateOrder(ctx, id, item, quantity)
+	order, err := s.repository.UpdateOrder(ctx, id, ownerFilter(ctx), item, quantity)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +69,7 @@ func (s *OrderService) UpdateOrder(ctx context.Context, id string, item string,
 }
 
 func (s *OrderService) DeleteOrder(ctx context.Context, id string) (bool, error) {
-	success, err := s.repository.DeleteOrder(ctx, id)
+	success, err := s.repository.DeleteOrder(ctx, id, ownerFilter(ctx))
 	if err != nil {
 		return success, err
 	}
@@ -78,10 +78,29 @@ func (s *OrderService) DeleteOrder(ctx context.Context, id string) (bool, error)
 }
 
 func (s *OrderService) ListOrders(ctx context.Context) ([]*api.Order, error) {
-	orders, err := s.repository.ListOrders(ctx)
+	orders, err := s.repository.ListOrders(ctx, ownerFilter(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	return orders, nil
 }
+
+func customerID(ctx context.Context) string {
+	if p, ok := auth.PrincipalFromCtx(ctx); ok {
+		return p.Subject
+	}
+
+	return ""
+}
+
+// ownerFilter returns the customer the caller is restricted to, or an empty
+// string when the caller may access every order (admins or auth disabled).
+func ownerFilter(ctx context.Context) string {
+	p, ok := auth.PrincipalFromCtx(ctx)
+	if !ok || p.HasRole(auth.RoleAdmin) {
+		return ""
+	}
+
+	return p.Subject
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/auth"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/service"
 	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
 )
@@ -16,33 +17,39 @@ type MockOrderRepository struct {
 	mock.Mock
 }
 
-func (m *MockOrderRepository) InsertOrder(ctx context.Context, item string, quantity int32) (string, error) {
-	args := m.Called(ctx, item, quantity)
+func (m *MockOrderRepository) InsertOrder(
+	ctx context.Context,
+	customerID string,
+	item string,
+	quantity int32,
+) (string, error) {
+	args := m.Called(ctx, customerID, item, quantity)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockOrderRepository) SelectOrder(ctx context.Context, id string) (*api.Order, error) {
-	args := m.Called(ctx, id)
+func (m *MockOrderRepository) SelectOrder(ctx context.Context, id string, customerID string) (*api.Order, error) {
+	args := m.Called(ctx, id, customerID)
 	return args.Get(0).(*api.Order), args.Error(1)
 }
 
 func (m *MockOrderRepository) UpdateOrder(
 	ctx context.Context,
 	id string,
+	customerID string,
 	item string,
 	quantity int32,
 ) (*api.Order, error) {
-	args := m.Called(ctx, id, item, quantity)
+	args := m.Called(ctx, id, customerID, item, quantity)
 	return args.Get(0).(*api.Order), args.Error(1)
 }
 
-func (m *MockOrderRepository) DeleteOrder(ctx context.Context, id string) (bool, error) {
-	args := m.Called(ctx, id)
+func (m *MockOrderRepository) DeleteOrder(ctx context.Context, id string, customerID string) (bool, error) {
+	args := m.Called(ctx, id, customerID)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockOrderRepository) ListOrders(ctx context.Context) ([]*api.Order, error) {
-	args := m.Called(ctx)
+func (m *MockOrderRepository) ListOrders(ctx context.Context, customerID string) ([]*api.Order, error) {
+	args := m.Called(ctx, customerID)
 	return args.Get(0).([]*api.Order), args.Error(1)
 }
 
@@ -57,7 +64,7 @@ func initialize() (*MockOrderRepository, *service.OrderService, context.Context)
 func TestOrderService_CreateOrder_Success(t *testing.T) {
 	mockRepo, service, ctx := initialize()
 
-	mockRepo.On("InsertOrder", ctx, "laptop", int32(3)).
+	mockRepo.On("InsertOrder", ctx, "", "laptop", int32(3)).
 		Return("123", nil)
 
 	id, err := service.CreateOrder(ctx, "laptop", 3)
@@ -90,7 +97,7 @@ func TestOrderService_GetOrder_Success(t *testing.T) {
 		Quantity: 1,
 	}
 
-	mockRepo.On("SelectOrder", ctx, "12").
+	mockRepo.On("SelectOrder", ctx, "12", "").
 		Return(expected, nil)
 
 	order, err := service.GetOrder(ctx, "12")
@@ -103,7 +110,7 @@ func TestOrderService_GetOrder_Success(t *testing.T) {
 func TestOrderService_GetOrder_NotFound(t *testing.T) {
 	mockRepo, service, ctx := initialize()
 
-	mockRepo.On("SelectOrder", ctx, "999").
+	mockRepo.On("SelectOrder", ctx, "999", "").
 		Return((*api.Order)(nil), errors.New("not found"))
 
 	order, err := service.GetOrder(ctx, "999")
@@ -112,3 +119,59 @@ func TestOrderService_GetOrder_NotFound(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestOrderService_CreateOrder_SetsCustomer(t *testing.T) {
+	mockRepo, service, ctx := initialize()
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Subject: "customer-1", Roles: []string{auth.RoleClient}})
+
+	mockRepo.On("InsertOrder", ctx, "customer-1", "laptop", int32(1)).
+		Return("123", nil)
+
+	id, err := service.CreateOrder(ctx, "laptop", 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "123", id)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderService_ScopedToCustomer(t *testing.T) {
+	mockRepo, service, ctx := initialize()
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Subject: "customer-1", Roles: []string{auth.RoleClient}})
+
+	order := &api.Order{Id: "12", Item: "bed", Quantity: 1, CustomerId: "customer-1"}
+	mockRepo.On("SelectOrder", ctx, "12", "customer-1").Return(order, nil)
+	mockRepo.On("UpdateOrder", ctx, "12", "customer-1", "sofa", int32(2)).Return(order, nil)
+	mockRepo.On("DeleteOrder", ctx, "12", "customer-1").Return(true, nil)
+	mockRepo.On("ListOrders", ctx, "customer-1").Return([]*api.Order{order}, nil)
+
+	_, err := service.GetOrder(ctx, "12")
+	require.NoError(t, err)
+
+	_, err = service.UpdateOrder(ctx, "12", "sofa", 2)
+	require.NoError(t, err)
+
+	_, err = service.DeleteOrder(ctx, "12")
+	require.NoError(t, err)
+
+	orders, err := service.ListOrders(ctx)
+	require.NoError(t, err)
+	assert.Len(t, orders, 1)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderService_AdminNotScoped(t *testing.T) {
+	mockRepo, service, ctx := initialize()
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Subject: "admin-1", Roles: []string{auth.RoleAdmin}})
+
+	mockRepo.On("ListOrders", ctx, "").Return([]*api.Order{}, nil)
+	mockRepo.On("DeleteOrder", ctx, "12", "").Return(true, nil)
+
+	_, err := service.ListOrders(ctx)
+	require.NoError(t, err)
+
+	_, err = service.DeleteOrder(ctx, "12")
+	require.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
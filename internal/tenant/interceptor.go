@@ -0,0 +1,117 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/auth"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/grpcutil"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const tenantHeader = "x-tenant-id"
+
+func TenantInterceptor(cfg TenantCfg) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		ctx, err := withResolvedTenant(ctx, cfg, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func TenantStreamInterceptor(cfg TenantCfg) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := withResolvedTenant(ss.Context(), cfg, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, grpcutil.WithContext(ss, ctx))
+	}
+}
+
+func withResolvedTenant(ctx context.Context, cfg TenantCfg, method string) (context.Context, error) {
+	tenantID, err := resolve(ctx, cfg)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Named("tenant").Warn(ctx, "tenant resolution failed",
+			zap.String("method", method),
+			zap.Error(err),
+		)
+		return ctx, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return WithTenant(ctx, tenantID), nil
+}
+
+// resolve prefers the tenant bound to the authenticated principal. The
+// x-tenant-id header may only contradict it for roles allowed to act across
+// tenants; other principals without a tenant get the default one. Without a
+// principal (auth disabled) the header is trusted as is.
+func resolve(ctx context.Context, cfg TenantCfg) (string, error) {
+	var requested string
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(tenantHeader); len(values) > 0 {
+		requested = values[0]
+	}
+
+	tenantID := requested
+	if p, ok := auth.PrincipalFromCtx(ctx); ok {
+		var err error
+		if tenantID, err = principalTenant(p, requested, cfg); err != nil {
+			return "", err
+		}
+	}
+
+	if tenantID == "" {
+		tenantID = cfg.DefaultTenant
+	}
+
+	if tenantID == "" {
+		return "", ErrMissingTenant
+	}
+
+	if err := Validate(tenantID); err != nil {
+		return "", err
+	}
+
+	return tenantID, nil
+}
+
+func principalTenant(p *auth.Principal, requested string, cfg TenantCfg) (string, error) {
+	if requested != "" && crossTenant(p, cfg.CrossTenantRoles) {
+		return requested, nil
+	}
+
+	own := p.TenantID
+	if own == "" {
+		own = cfg.DefaultTenant
+	}
+	if requested != "" && requested != own {
+		return "", fmt.Errorf("%w: caller belongs to another tenant", ErrInvalidTenant)
+	}
+
+	return own, nil
+}
+
+func crossTenant(p *auth.Principal, roles []string) bool {
+	for _, role := range roles {
+		if p.HasRole(role) {
+			return true
+		}
+	}
+
+	return false
+}
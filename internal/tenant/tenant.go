@@ -0,0 +1,50 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+type tenantKey string
+
+const (
+	key tenantKey = "tenant"
+)
+
+var (
+	ErrMissingTenant = errors.New("missing tenant")
+	ErrInvalidTenant = errors.New("invalid tenant")
+
+	tenantPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+)
+
+// TenantCfg: only principals with one of CrossTenantRoles may pick a tenant
+// other than their own through x-tenant-id. The default is the dedicated
+// platform_admin role, the per-tenant admin role must not cross tenants.
+type TenantCfg struct {
+	DefaultTenant    string   `env:"TENANT_DEFAULT"            env-default:"default"`
+	CrossTenantRoles []string `env:"TENANT_CROSS_TENANT_ROLES" env-default:"platform_admin" env-separator:","`
+}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, key, tenantID)
+}
+
+func FromCtx(ctx context.Context) (string, error) {
+	tenantID, ok := ctx.Value(key).(string)
+	if !ok || tenantID == "" {
+		return "", ErrMissingTenant
+	}
+
+	return tenantID, nil
+}
+
+func Validate(tenantID string) error {
+	if !tenantPattern.MatchString(tenantID) {
+		return fmt.Errorf("%w: %q", ErrInvalidTenant, tenantID)
+	}
+
+	return nil
+}
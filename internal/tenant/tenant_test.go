@@ -0,0 +1,148 @@
+package tenant_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/auth"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tenant"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTenantInterceptor(t *testing.T) {
+	rootCtx, err := logger.New(context.Background(), "")
+	require.NoError(t, err)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.OrderService/GetOrder"}
+	handler := func(ctx context.Context, _ any) (any, error) {
+		return tenant.FromCtx(ctx)
+	}
+
+	tests := []struct {
+		name          string
+		defaultTenant string
+		principal     *auth.Principal
+		header        string
+		expected      string
+		code          codes.Code
+	}{
+		{
+			name:          "default tenant",
+			defaultTenant: "default",
+			expected:      "default",
+		},
+		{
+			name:          "tenant from header",
+			defaultTenant: "default",
+			header:        "shop-a",
+			expected:      "shop-a",
+		},
+		{
+			name:          "tenant from principal",
+			defaultTenant: "default",
+			principal:     &auth.Principal{Subject: "user-1", TenantID: "shop-b"},
+			expected:      "shop-b",
+		},
+		{
+			name:          "header matching principal",
+			defaultTenant: "default",
+			principal:     &auth.Principal{Subject: "user-1", TenantID: "shop-b"},
+			header:        "shop-b",
+			expected:      "shop-b",
+		},
+		{
+			name:          "header contradicting principal",
+			defaultTenant: "default",
+			principal:     &auth.Principal{Subject: "user-1", TenantID: "shop-b"},
+			header:        "shop-a",
+			code:          codes.PermissionDenied,
+		},
+		{
+			name:          "principal without tenant gets default",
+			defaultTenant: "default",
+			principal:     &auth.Principal{Subject: "user-1", Roles: []string{"client"}},
+			expected:      "default",
+		},
+		{
+			name:          "principal without tenant cannot pick one",
+			defaultTenant: "default",
+			principal:     &auth.Principal{Subject: "user-1", Roles: []string{"client"}},
+			header:        "shop-a",
+			code:          codes.PermissionDenied,
+		},
+		{
+			name:      "principal without tenant and default is rejected",
+			principal: &auth.Principal{Subject: "user-1", Roles: []string{"client"}},
+			header:    "shop-a",
+			code:      codes.PermissionDenied,
+		},
+		{
+			name:          "cross-tenant role picks tenant from header",
+			defaultTenant: "default",
+			principal:     &auth.Principal{Subject: "ops", TenantID: "shop-b", Roles: []string{auth.RolePlatformAdmin}},
+			header:        "shop-a",
+			expected:      "shop-a",
+		},
+		{
+			name:          "cross-tenant role defaults to own tenant",
+			defaultTenant: "default",
+			principal:     &auth.Principal{Subject: "ops", TenantID: "shop-b", Roles: []string{auth.RolePlatformAdmin}},
+			expected:      "shop-b",
+		},
+		{
+			name:          "tenant admin cannot switch tenants",
+			defaultTenant: "default",
+			principal:     &auth.Principal{Subject: "owner", TenantID: "shop-b", Roles: []string{auth.RoleAdmin}},
+			header:        "shop-a",
+			code:          codes.PermissionDenied,
+		},
+		{
+			name: "missing tenant without default",
+			code: codes.PermissionDenied,
+		},
+		{
+			name:          "invalid tenant",
+			defaultTenant: "default",
+			header:        "shop:a",
+			code:          codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := rootCtx
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+			if tt.header != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-tenant-id", tt.header))
+			}
+
+			interceptor := tenant.TenantInterceptor(tenant.TenantCfg{
+				DefaultTenant:    tt.defaultTenant,
+				CrossTenantRoles: []string{auth.RolePlatformAdmin},
+			})
+			resp, err := interceptor(ctx, nil, info, handler)
+
+			if tt.code != codes.OK {
+				require.Error(t, err)
+				assert.Equal(t, tt.code, status.Code(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestFromCtx_Missing(t *testing.T) {
+	_, err := tenant.FromCtx(context.Background())
+	require.ErrorIs(t, err, tenant.ErrMissingTenant)
+}
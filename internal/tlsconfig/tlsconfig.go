@@ -0,0 +1,227 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var ErrNoCertificates = errors.New("no certificates found")
+
+// TLSCfg configures the gRPC listener and the gateway. The gateway dials the
+// gRPC server with CAFile as trust root and, when client certificates are
+// required, presents GatewayCertFile/GatewayKeyFile.
+type TLSCfg struct {
+	Enabled           bool          `env:"TLS_ENABLED"                 env-default:"false"`
+	CertFile          string        `env:"TLS_CERT_FILE"`
+	KeyFile           string        `env:"TLS_KEY_FILE"`
+	ClientCAFile      string        `env:"TLS_CLIENT_CA_FILE"`
+	RequireClientCert bool          `env:"TLS_REQUIRE_CLIENT_CERT"     env-default:"false"`
+	GatewayHTTPS      bool          `env:"TLS_GATEWAY_HTTPS"           env-default:"false"`
+	CAFile            string        `env:"TLS_CA_FILE"`
+	ServerName        string        `env:"TLS_SERVER_NAME"             env-default:"localhost"`
+	GatewayCertFile   string        `env:"TLS_GATEWAY_CLIENT_CERT_FILE"`
+	GatewayKeyFile    string        `env:"TLS_GATEWAY_CLIENT_KEY_FILE"`
+	ReloadInterval    time.Duration `env:"TLS_RELOAD_INTERVAL"         env-default:"1m"`
+}
+
+// NewServerConfig builds a server config whose certificate and client CA
+// pool are re-read from disk when the files change, so rotated certificates
+// are picked up without a restart.
+func NewServerConfig(ctx context.Context, cfg TLSCfg) (*tls.Config, error) {
+	cert, err := newKeyPairReloader(ctx, cfg.CertFile, cfg.KeyFile, cfg.ReloadInterval)
+	if err != nil {
+		return nil, fmt.Errorf("server certificate: %w", err)
+	}
+
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cert.getCertificate,
+	}
+	if cfg.ClientCAFile == "" {
+		return base, nil
+	}
+
+	clientCAs, err := newPoolReloader(ctx, cfg.ClientCAFile, cfg.ReloadInterval)
+	if err != nil {
+		return nil, fmt.Errorf("client CA: %w", err)
+	}
+
+	clientAuth := tls.VerifyClientCertIfGiven
+	if cfg.RequireClientCert {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := base.Clone()
+		c.GetConfigForClient = nil
+		c.ClientAuth = clientAuth
+		c.ClientCAs = clientCAs.current()
+		return c, nil
+	}
+
+	return base, nil
+}
+
+// NewClientConfig builds the config the gateway uses to dial the gRPC server.
+func NewClientConfig(ctx context.Context, cfg TLSCfg) (*tls.Config, error) {
+	c := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerName,
+	}
+
+	if cfg.CAFile != "" {
+		roots, err := loadPool(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("CA: %w", err)
+		}
+		c.RootCAs = roots
+	}
+
+	if cfg.GatewayCertFile != "" {
+		cert, err := newKeyPairReloader(ctx, cfg.GatewayCertFile, cfg.GatewayKeyFile, cfg.ReloadInterval)
+		if err != nil {
+			return nil, fmt.Errorf("gateway client certificate: %w", err)
+		}
+		c.GetClientCertificate = cert.getClientCertificate
+	}
+
+	return c, nil
+}
+
+type fileReloader[T any] struct {
+	mu      sync.RWMutex
+	value   T
+	files   []string
+	modTime []time.Time
+	load    func() (T, error)
+}
+
+func newFileReloader[T any](ctx context.Context, interval time.Duration, load func() (T, error), files ...string) (*fileReloader[T], error) {
+	r := &fileReloader[T]{files: files, load: load, modTime: make([]time.Time, len(files))}
+	if _, err := r.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go r.watch(ctx, interval)
+	}
+
+	return r, nil
+}
+
+func (r *fileReloader[T]) current() T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.value
+}
+
+func (r *fileReloader[T]) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reloadIfChanged()
+			log := logger.GetLoggerFromCtx(ctx).Named("tls")
+			switch {
+			case err != nil:
+				log.Error(ctx, "failed to reload TLS files, keeping previous", zap.Strings("files", r.files), zap.Error(err))
+			case reloaded:
+				log.Info(ctx, "TLS files reloaded", zap.Strings("files", r.files))
+			}
+		}
+	}
+}
+
+func (r *fileReloader[T]) reloadIfChanged() (bool, error) {
+	modTime := make([]time.Time, len(r.files))
+	changed := false
+	for i, file := range r.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, fmt.Errorf("stat %s: %w", file, err)
+		}
+		modTime[i] = info.ModTime()
+		changed = changed || !modTime[i].Equal(r.modTime[i])
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	value, err := r.load()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	r.value = value
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+type keyPairReloader struct {
+	*fileReloader[*tls.Certificate]
+}
+
+func newKeyPairReloader(ctx context.Context, certFile, keyFile string, interval time.Duration) (*keyPairReloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("certificate and key files are required")
+	}
+
+	r, err := newFileReloader(ctx, interval, func() (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load key pair: %w", err)
+		}
+		return &cert, nil
+	}, certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keyPairReloader{r}, nil
+}
+
+func (r *keyPairReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+func (r *keyPairReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+func newPoolReloader(ctx context.Context, file string, interval time.Duration) (*fileReloader[*x509.CertPool], error) {
+	return newFileReloader(ctx, interval, func() (*x509.CertPool, error) {
+		return loadPool(file)
+	}, file)
+}
+
+func loadPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", file, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: %w", file, ErrNoCertificates)
+	}
+
+	return pool, nil
+}
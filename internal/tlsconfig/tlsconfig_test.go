@@ -0,0 +1,221 @@
+package tlsconfig_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/tlsconfig"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger/loggertest"
+	"go.uber.org/zap"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes a leaf certificate signed by the CA to dir and returns the
+// cert and key paths.
+func (ca *testCA) issue(t *testing.T, dir, name string, serial int64) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+type accepted struct {
+	cn  string
+	err error
+}
+
+// handshake connects client to a TLS listener using server config and
+// returns the server certificate serial and the client CN seen by the server.
+func handshake(t *testing.T, server, client *tls.Config) (int64, string, error) {
+	t.Helper()
+
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	seen := make(chan accepted, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			seen <- accepted{err: err}
+			return
+		}
+		defer conn.Close()
+
+		tlsConn := conn.(*tls.Conn)
+		if err := tlsConn.Handshake(); err != nil {
+			seen <- accepted{err: err}
+			return
+		}
+
+		var cn string
+		if peers := tlsConn.ConnectionState().PeerCertificates; len(peers) > 0 {
+			cn = peers[0].Subject.CommonName
+		}
+		seen <- accepted{cn: cn}
+	}()
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", lis.Addr().String(), client)
+	if err != nil {
+		<-seen
+		return 0, "", err
+	}
+	defer conn.Close()
+
+	res := <-seen
+	serial := conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+
+	return serial, res.cn, res.err
+}
+
+func TestMutualTLS(t *testing.T) {
+	ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+	dir := t.TempDir()
+	ca := newCA(t)
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+
+	serverCert, serverKey := ca.issue(t, dir, "server", 10)
+	gatewayCert, gatewayKey := ca.issue(t, dir, "gateway", 11)
+
+	cfg := tlsconfig.TLSCfg{
+		CertFile:          serverCert,
+		KeyFile:           serverKey,
+		ClientCAFile:      caFile,
+		RequireClientCert: true,
+		CAFile:            caFile,
+		ServerName:        "localhost",
+		GatewayCertFile:   gatewayCert,
+		GatewayKeyFile:    gatewayKey,
+	}
+
+	server, err := tlsconfig.NewServerConfig(ctx, cfg)
+	require.NoError(t, err)
+	client, err := tlsconfig.NewClientConfig(ctx, cfg)
+	require.NoError(t, err)
+
+	serial, cn, err := handshake(t, server, client)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), serial)
+	assert.Equal(t, "gateway", cn)
+
+	anonymous := &tls.Config{RootCAs: client.RootCAs, ServerName: "localhost", MinVersion: tls.VersionTLS12}
+	_, _, err = handshake(t, server, anonymous)
+	assert.Error(t, err, "client certificate is required")
+}
+
+func TestServerConfig_ReloadsRotatedCertificate(t *testing.T) {
+	ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	dir := t.TempDir()
+	ca := newCA(t)
+	certFile, keyFile := ca.issue(t, dir, "server", 1)
+
+	server, err := tlsconfig.NewServerConfig(ctx, tlsconfig.TLSCfg{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ReloadInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := &tls.Config{RootCAs: roots, ServerName: "localhost", MinVersion: tls.VersionTLS12}
+
+	serial, _, err := handshake(t, server, client)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), serial)
+
+	rotated := t.TempDir()
+	newCert, newKey := ca.issue(t, rotated, "server", 2)
+	future := time.Now().Add(time.Minute)
+	for _, f := range [][2]string{{newCert, certFile}, {newKey, keyFile}} {
+		require.NoError(t, os.Rename(f[0], f[1]))
+		require.NoError(t, os.Chtimes(f[1], future, future))
+	}
+
+	assert.Eventually(t, func() bool {
+		serial, _, err := handshake(t, server, client)
+		return err == nil && serial == 2
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestNewServerConfig_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := tlsconfig.NewServerConfig(ctx, tlsconfig.TLSCfg{})
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := newCA(t).issue(t, dir, "server", 1)
+	badCA := filepath.Join(dir, "bad.pem")
+	require.NoError(t, os.WriteFile(badCA, []byte("not a certificate"), 0o600))
+
+	_, err = tlsconfig.NewServerConfig(ctx, tlsconfig.TLSCfg{CertFile: certFile, KeyFile: keyFile, ClientCAFile: badCA})
+	assert.ErrorIs(t, err, tlsconfig.ErrNoCertificates)
+}
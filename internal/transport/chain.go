@@ -0,0 +1,76 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	InterceptorRecovery   = "recovery"
+	InterceptorRequestID  = "request_id"
+	InterceptorLogging    = "logging"
+	InterceptorMetrics    = "metrics"
+	InterceptorAuth       = "auth"
+	InterceptorTenant     = "tenant"
+	InterceptorRateLimit  = "rate_limit"
+	InterceptorValidation = "validation"
+)
+
+var ErrUnknownInterceptor = errors.New("unknown interceptor")
+
+// InterceptorsCfg lists server interceptors from outermost to innermost.
+// Recovery should stay first so panics in the others are caught too.
+type InterceptorsCfg struct {
+	Interceptors []string `env:"GRPC_INTERCEPTORS" env-default:"recovery,request_id,logging,metrics,auth,tenant,rate_limit,validation" env-separator:","`
+}
+
+// Interceptor is one stage of the chain. A stage may consist of several
+// interceptors (authentication and authorization both belong to "auth").
+type Interceptor struct {
+	Unary  grpc.UnaryServerInterceptor
+	Stream grpc.StreamServerInterceptor
+}
+
+// Chain orders the available interceptors as listed in order. Known names
+// without an entry in available (the feature is switched off) are skipped;
+// unknown or repeated names are configuration errors.
+func Chain(
+	order []string,
+	available map[string][]Interceptor,
+) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor, error) {
+	known := []string{
+		InterceptorRecovery, InterceptorRequestID, InterceptorLogging, InterceptorMetrics,
+		InterceptorAuth, InterceptorTenant, InterceptorRateLimit, InterceptorValidation,
+	}
+
+	var (
+		unary  []grpc.UnaryServerInterceptor
+		stream []grpc.StreamServerInterceptor
+		seen   []string
+	)
+	for _, name := range order {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(known, name) {
+			return nil, nil, fmt.Errorf("%w %q", ErrUnknownInterceptor, name)
+		}
+		if slices.Contains(seen, name) {
+			return nil, nil, fmt.Errorf("interceptor %q listed twice", name)
+		}
+		seen = append(seen, name)
+
+		for _, i := range available[name] {
+			if i.Unary != nil {
+				unary = append(unary, i.Unary)
+			}
+			if i.Stream != nil {
+				stream = append(stream, i.Stream)
+			}
+		}
+	}
+
+	return unary, stream, nil
+}
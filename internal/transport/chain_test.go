@@ -0,0 +1,68 @@
+package transport_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	"google.golang.org/grpc"
+)
+
+func recordingInterceptor(calls *[]string, name string) transport.Interceptor {
+	return transport.Interceptor{
+		Unary: func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			*calls = append(*calls, name)
+			return handler(ctx, req)
+		},
+		Stream: func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			*calls = append(*calls, name)
+			return handler(srv, ss)
+		},
+	}
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	available := map[string][]transport.Interceptor{
+		transport.InterceptorRecovery: {recordingInterceptor(&calls, "recovery")},
+		transport.InterceptorLogging:  {recordingInterceptor(&calls, "logging")},
+		transport.InterceptorAuth: {
+			recordingInterceptor(&calls, "authn"),
+			recordingInterceptor(&calls, "authz"),
+		},
+	}
+
+	t.Run("follows configured order and skips disabled stages", func(t *testing.T) {
+		order := []string{"recovery", " auth", "rate_limit", "logging"}
+		unary, stream, err := transport.Chain(order, available)
+		require.NoError(t, err)
+
+		info := &grpc.UnaryServerInfo{FullMethod: "/test/Unary"}
+		next := grpc.UnaryHandler(func(context.Context, any) (any, error) { return nil, nil })
+		for i := len(unary) - 1; i >= 0; i-- {
+			interceptor, inner := unary[i], next
+			next = func(ctx context.Context, req any) (any, error) { return interceptor(ctx, req, info, inner) }
+		}
+		_, err = next(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"recovery", "authn", "authz", "logging"}, calls)
+
+		calls = nil
+		require.Len(t, stream, 4)
+		streamInfo := &grpc.StreamServerInfo{FullMethod: "/test/Stream"}
+		require.NoError(t, stream[0](nil, nil, streamInfo, func(any, grpc.ServerStream) error { return nil }))
+		assert.Equal(t, []string{"recovery"}, calls)
+	})
+
+	t.Run("rejects unknown interceptor", func(t *testing.T) {
+		_, _, err := transport.Chain([]string{"recovery", "tracing"}, available)
+		require.ErrorIs(t, err, transport.ErrUnknownInterceptor)
+	})
+
+	t.Run("rejects duplicate interceptor", func(t *testing.T) {
+		_, _, err := transport.Chain([]string{"logging", "logging"}, available)
+		require.Error(t, err)
+	})
+}
@@ -2,36 +2,302 @@ package transport
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"mime"
+	"net"
 	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport/openapi"
 	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
-func StartGateway(ctx context.Context, grpcPort, gatewayPort string) (*http.Server, error) {
-	mux := runtime.NewServeMux()
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+const (
+	mimeJSON      = "application/json"
+	mimeProtobuf  = "application/protobuf"
+	mimeXProtobuf = "application/x-protobuf"
 
-	if err := api.RegisterOrderServiceHandlerFromEndpoint(ctx, mux, "localhost:"+grpcPort, opts); err != nil {
+	GatewayModeDial      = "dial"
+	GatewayModeInProcess = "inprocess"
+
+	inProcessNetwork    = "bufconn"
+	inProcessBufferSize = 1 << 20
+)
+
+// GatewayCfg configures the gateway and its HTTP middleware. CORS is off
+// while GATEWAY_CORS_ALLOWED_ORIGINS is empty, "*" allows any origin.
+type GatewayCfg struct {
+	CORSAllowedOrigins   []string      `env:"GATEWAY_CORS_ALLOWED_ORIGINS"   env-separator:","`
+	CORSAllowedMethods   []string      `env:"GATEWAY_CORS_ALLOWED_METHODS"   env-default:"GET,POST,PUT,DELETE" env-separator:","`
+	CORSAllowedHeaders   []string      `env:"GATEWAY_CORS_ALLOWED_HEADERS"   env-default:"Authorization,Content-Type,X-Api-Key,X-Tenant-Id,X-Request-Id" env-separator:","`
+	CORSExposedHeaders   []string      `env:"GATEWAY_CORS_EXPOSED_HEADERS"   env-default:"Retry-After,X-Request-Id" env-separator:","`
+	CORSAllowCredentials bool          `env:"GATEWAY_CORS_ALLOW_CREDENTIALS" env-default:"false"`
+	CORSMaxAge           time.Duration `env:"GATEWAY_CORS_MAX_AGE"           env-default:"10m"`
+	CompressionEnabled   bool          `env:"GATEWAY_COMPRESSION_ENABLED"    env-default:"true"`
+	CompressionMinSize   int           `env:"GATEWAY_COMPRESSION_MIN_SIZE"   env-default:"1024"`
+	MaxBodyBytes         int64         `env:"GATEWAY_MAX_BODY_BYTES"         env-default:"1048576"`
+	SecurityHeaders      bool          `env:"GATEWAY_SECURITY_HEADERS"       env-default:"true"`
+	AccessLog            bool          `env:"GATEWAY_ACCESS_LOG"             env-default:"true"`
+	JSONUseProtoNames    bool          `env:"GATEWAY_JSON_USE_PROTO_NAMES"    env-default:"false"`
+	JSONEmitUnpopulated  bool          `env:"GATEWAY_JSON_EMIT_UNPOPULATED"   env-default:"true"`
+	JSONEnumsAsNumbers   bool          `env:"GATEWAY_JSON_ENUMS_AS_NUMBERS"   env-default:"false"`
+	JSONDiscardUnknown   bool          `env:"GATEWAY_JSON_DISCARD_UNKNOWN"    env-default:"true"`
+	Mode                 string        `env:"GATEWAY_MODE"                    env-default:"inprocess"`
+	SinglePort           bool          `env:"GATEWAY_SINGLE_PORT"             env-default:"false"`
+}
+
+type GatewayParams struct {
+	GRPCServer  *grpc.Server
+	GRPCPort    string
+	GatewayPort string
+	Routes      map[string]http.Handler
+	DialCreds   credentials.TransportCredentials
+	TLS         *tls.Config
+	// MaxRecvMsgSize matches the server's send limit so large responses
+	// are not rejected by the gateway's client-side default.
+	MaxRecvMsgSize int
+}
+
+// StartGateway serves the REST gateway. In inprocess mode (and always with
+// SinglePort) the gateway talks to GRPCServer over an in-memory listener, so
+// requests still pass through the server's interceptors without a network
+// hop. SinglePort additionally serves gRPC itself on the gateway port.
+func StartGateway(ctx context.Context, cfg GatewayCfg, p GatewayParams) (*http.Server, error) {
+	log := logger.GetLoggerFromCtx(ctx)
+
+	conn, err := gatewayConn(ctx, cfg, p)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := NewGatewayMux(log, cfg)
+	if err = api.RegisterOrderServiceHandler(ctx, mux, conn); err != nil {
 		return nil, fmt.Errorf("failed to register order service handler: %w", err)
 	}
 
+	httpMux := http.NewServeMux()
+	httpMux.Handle(openapi.SpecPath, openapi.SpecHandler())
+	httpMux.Handle(openapi.DocsPath, openapi.DocsHandler())
+	for pattern, handler := range p.Routes {
+		httpMux.Handle(pattern, handler)
+	}
+	httpMux.Handle("/", NegotiateAccept(mux))
+
+	var handler http.Handler = otelhttp.NewHandler(GatewayMiddleware(log, cfg, httpMux), "gateway")
+	if cfg.SinglePort {
+		handler = GRPCOrHTTP(p.GRPCServer, handler)
+		if p.TLS == nil {
+			handler = h2c.NewHandler(handler, &http2.Server{})
+		}
+	}
+
 	const defaultGatewayTimeout = 5 * time.Second
 	server := &http.Server{
-		Addr:              ":" + gatewayPort,
-		Handler:           mux,
+		Addr:              ":" + p.GatewayPort,
+		Handler:           handler,
 		ReadHeaderTimeout: defaultGatewayTimeout,
+		TLSConfig:         p.TLS,
 	}
+	server.RegisterOnShutdown(func() {
+		_ = conn.Close()
+	})
 
 	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		serve := server.ListenAndServe
+		if p.TLS != nil {
+			serve = func() error { return server.ListenAndServeTLS("", "") }
+		}
+
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			panic(fmt.Errorf("failed to start gateway: %w", err))
 		}
 	}()
 
 	return server, nil
 }
+
+func gatewayConn(ctx context.Context, cfg GatewayCfg, p GatewayParams) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(p.DialCreds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	}
+	if p.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(p.MaxRecvMsgSize)))
+	}
+	target := "localhost:" + p.GRPCPort
+
+	if cfg.Mode == GatewayModeInProcess || cfg.SinglePort {
+		lis := bufconn.Listen(inProcessBufferSize)
+		go func() {
+			if err := p.GRPCServer.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				logger.GetLoggerFromCtx(ctx).Error(ctx, "in-process gRPC listener failed", zap.Error(err))
+			}
+		}()
+
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}))
+		target = "passthrough:///" + inProcessNetwork
+	}
+
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gateway connection: %w", err)
+	}
+
+	return conn, nil
+}
+
+// Shutdown stops the gateway and the gRPC server. With SinglePort gRPC calls
+// arrive through grpc.Server.ServeHTTP, whose transports don't implement
+// Drain, so GracefulStop would panic on any open call; the gateway is drained
+// first and the gRPC server stopped after it instead.
+func Shutdown(ctx context.Context, cfg GatewayCfg, grpcServer *grpc.Server, gateway *http.Server) error {
+	if !cfg.SinglePort {
+		grpcServer.GracefulStop()
+		return gateway.Shutdown(ctx)
+	}
+
+	err := gateway.Shutdown(ctx)
+	grpcServer.Stop()
+
+	return err
+}
+
+// GRPCOrHTTP routes HTTP/2 requests with a gRPC content type to the gRPC
+// server and everything else to httpHandler, so both share one listener.
+func GRPCOrHTTP(grpcServer *grpc.Server, httpHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+
+		httpHandler.ServeHTTP(w, r)
+	})
+}
+
+func NewGatewayMux(log *logger.Logger, cfg GatewayCfg) *runtime.ServeMux {
+	jsonMarshaler := &runtime.HTTPBodyMarshaler{
+		Marshaler: &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   cfg.JSONUseProtoNames,
+				EmitUnpopulated: cfg.JSONEmitUnpopulated,
+				UseEnumNumbers:  cfg.JSONEnumsAsNumbers,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: cfg.JSONDiscardUnknown,
+			},
+		},
+	}
+	opts := []runtime.ServeMuxOption{
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		runtime.WithErrorHandler(ProblemErrorHandler(log)),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, jsonMarshaler),
+	}
+	for _, mediaType := range []string{mimeProtobuf, mimeXProtobuf} {
+		opts = append(opts, runtime.WithMarshalerOption(mediaType, &protoMarshaler{contentType: mediaType}))
+	}
+
+	return runtime.NewServeMux(opts...)
+}
+
+// protoMarshaler answers with the media type the client asked for instead
+// of application/octet-stream.
+type protoMarshaler struct {
+	runtime.ProtoMarshaller
+	contentType string
+}
+
+func (m *protoMarshaler) ContentType(any) string {
+	return m.contentType
+}
+
+// NegotiateAccept reduces the Accept header to the supported media type with
+// the highest q-value, the gateway only matches Accept values verbatim.
+// Types with q=0 are refused by the client and never chosen.
+func NegotiateAccept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mediaType := preferredMediaType(r.Header.Values("Accept")); mediaType != "" {
+			r.Header.Set("Accept", mediaType)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// preferredMediaType returns the first of the highest-q supported types, or
+// "" when the header names none of them.
+func preferredMediaType(accepts []string) string {
+	best, bestQ := "", 0.0
+	for _, accept := range accepts {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			q := 1.0
+			if value, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(value, 64); err != nil {
+					continue
+				}
+			}
+
+			switch mediaType {
+			case mimeProtobuf, mimeXProtobuf:
+			case mimeJSON, "application/*", "*/*":
+				mediaType = mimeJSON
+			default:
+				continue
+			}
+
+			if q > bestQ {
+				best, bestQ = mediaType, q
+			}
+		}
+	}
+
+	return best
+}
+
+func incomingHeaderMatcher(key string) (string, bool) {
+	switch textproto.CanonicalMIMEHeaderKey(key) {
+	case "X-Api-Key":
+		return "x-api-key", true
+	case "X-Tenant-Id":
+		return "x-tenant-id", true
+	case "X-Request-Id":
+		return logger.RequestIDHeader, true
+	default:
+		return runtime.DefaultHeaderMatcher(key)
+	}
+}
+
+func outgoingHeaderMatcher(key string) (string, bool) {
+	switch key {
+	case retryAfterHeader:
+		return "Retry-After", true
+	case logger.RequestIDHeader:
+		return "X-Request-Id", true
+	}
+
+	return runtime.MetadataHeaderPrefix + key, true
+}
@@ -0,0 +1,258 @@
+package transport_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger/loggertest"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+func newGatewayMux(t *testing.T, cfg transport.GatewayCfg) http.Handler {
+	t.Helper()
+
+	mockService := new(MockOrderService)
+	mockService.On("GetOrder", mock.Anything, "1").
+		Return(&api.Order{Id: "1", Item: "laptop", Quantity: 0, CustomerId: "alice"}, nil)
+
+	ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+	mux := transport.NewGatewayMux(logger.GetLoggerFromCtx(ctx), cfg)
+	require.NoError(t, api.RegisterOrderServiceHandlerServer(ctx, mux, transport.NewOrderServer(mockService)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport.NegotiateAccept(mux).ServeHTTP(w, r.WithContext(logger.WithLogger(r.Context(), logger.GetLoggerFromCtx(ctx))))
+	})
+}
+
+func getOrder(h http.Handler, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/1", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestGatewayMux_JSONOptions(t *testing.T) {
+	t.Run("camelCase with unpopulated fields", func(t *testing.T) {
+		rec := getOrder(newGatewayMux(t, transport.GatewayCfg{JSONEmitUnpopulated: true}), "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var body map[string]map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Contains(t, body["order"], "customerId")
+		assert.EqualValues(t, 0, body["order"]["quantity"])
+	})
+
+	t.Run("snake_case without unpopulated fields", func(t *testing.T) {
+		rec := getOrder(newGatewayMux(t, transport.GatewayCfg{JSONUseProtoNames: true}), "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var body map[string]map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Contains(t, body["order"], "customer_id")
+		assert.NotContains(t, body["order"], "quantity")
+	})
+}
+
+func TestGatewayMux_ProtobufNegotiation(t *testing.T) {
+	h := newGatewayMux(t, transport.GatewayCfg{})
+
+	for _, accept := range []string{
+		"application/x-protobuf",
+		"application/protobuf",
+		"application/x-protobuf;q=0.9, application/json;q=0.5",
+		"application/json;q=0.5, text/html, application/protobuf;q=0.8",
+	} {
+		t.Run(accept, func(t *testing.T) {
+			rec := getOrder(h, accept)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.True(t, strings.HasSuffix(rec.Header().Get("Content-Type"), "protobuf"))
+
+			var resp api.GetOrderResponse
+			require.NoError(t, proto.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, "laptop", resp.GetOrder().GetItem())
+		})
+	}
+
+	for _, accept := range []string{
+		"application/json",
+		"application/x-protobuf;q=0, application/json",
+		"application/protobuf;q=0.5, */*",
+		"text/html, application/x-protobuf;q=0",
+	} {
+		t.Run(accept, func(t *testing.T) {
+			rec := getOrder(h, accept)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestGatewayMux_ForwardsAuthorizationOnce(t *testing.T) {
+	var authorization []string
+	mockService := new(MockOrderService)
+	mockService.On("GetOrder", mock.Anything, "1").
+		Run(func(args mock.Arguments) {
+			md, _ := metadata.FromIncomingContext(args.Get(0).(context.Context))
+			authorization = md.Get("authorization")
+		}).
+		Return(&api.Order{Id: "1"}, nil)
+
+	ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+	mux := transport.NewGatewayMux(logger.GetLoggerFromCtx(ctx), transport.GatewayCfg{})
+	require.NoError(t, api.RegisterOrderServiceHandlerServer(ctx, mux, transport.NewOrderServer(mockService)))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/1", nil).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"Bearer token"}, authorization)
+}
+
+func freePort(t *testing.T) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	_, port, err := net.SplitHostPort(lis.Addr().String())
+	require.NoError(t, err)
+
+	return port
+}
+
+func TestStartGateway_InProcess(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  transport.GatewayCfg
+	}{
+		{name: "in-process connection", cfg: transport.GatewayCfg{Mode: transport.GatewayModeInProcess}},
+		{name: "single port", cfg: transport.GatewayCfg{SinglePort: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+
+			mockService := new(MockOrderService)
+			mockService.On("GetOrder", mock.Anything, "1").Return(&api.Order{Id: "1", Item: "laptop", Quantity: 1}, nil)
+
+			var intercepted atomic.Int32
+			grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+				logger.LoggerInterceptor(ctx),
+				func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+					intercepted.Add(1)
+					return handler(ctx, req)
+				},
+			))
+			api.RegisterOrderServiceServer(grpcServer, transport.NewOrderServer(mockService))
+			defer grpcServer.Stop()
+
+			port := freePort(t)
+			server, err := transport.StartGateway(ctx, tt.cfg, transport.GatewayParams{
+				GRPCServer:  grpcServer,
+				GRPCPort:    "1",
+				GatewayPort: port,
+				DialCreds:   insecure.NewCredentials(),
+			})
+			require.NoError(t, err)
+			defer server.Close()
+
+			var resp *http.Response
+			require.Eventually(t, func() bool {
+				resp, err = http.Get("http://127.0.0.1:" + port + "/api/v1/orders/1")
+				return err == nil
+			}, 2*time.Second, 10*time.Millisecond)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.EqualValues(t, 1, intercepted.Load(), "gateway requests go through server interceptors")
+
+			if !tt.cfg.SinglePort {
+				return
+			}
+
+			conn, err := grpc.NewClient("127.0.0.1:"+port, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			order, err := api.NewOrderServiceClient(conn).GetOrder(ctx, &api.GetOrderRequest{Id: "1"})
+			require.NoError(t, err)
+			assert.Equal(t, "laptop", order.GetOrder().GetItem())
+		})
+	}
+}
+
+func TestShutdown_SinglePortWithOpenCall(t *testing.T) {
+	ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+
+	mockService := new(MockOrderService)
+	mockService.On("GetOrder", mock.Anything, "1").
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).
+		Return(&api.Order{Id: "1"}, nil)
+
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(logger.LoggerInterceptor(ctx)))
+	api.RegisterOrderServiceServer(grpcServer, transport.NewOrderServer(mockService))
+
+	cfg := transport.GatewayCfg{SinglePort: true}
+	port := freePort(t)
+	server, err := transport.StartGateway(ctx, cfg, transport.GatewayParams{
+		GRPCServer:  grpcServer,
+		GatewayPort: port,
+		DialCreds:   insecure.NewCredentials(),
+	})
+	require.NoError(t, err)
+
+	conn, err := grpc.NewClient("127.0.0.1:"+port, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	callErr := make(chan error, 1)
+	go func() {
+		_, err := api.NewOrderServiceClient(conn).GetOrder(ctx, &api.GetOrderRequest{Id: "1"}, grpc.WaitForReady(true))
+		callErr <- err
+	}()
+	<-started
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	require.NotPanics(t, func() {
+		_ = transport.Shutdown(shutdownCtx, cfg, grpcServer, server)
+	})
+
+	select {
+	case err := <-callErr:
+		require.Error(t, err, "open call is cut off by Stop")
+	case <-time.After(2 * time.Second):
+		t.Fatal("open call was not terminated by shutdown")
+	}
+}
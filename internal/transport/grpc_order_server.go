@@ -2,13 +2,17 @@ package transport
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
-	"strings"
 
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/patterns"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/repository/database"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/service"
 	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -49,10 +53,10 @@ func (s *OrderServer) Start(ctx context.Context, grpcServer *grpc.Server, port s
 }
 
 func (s *OrderServer) CreateOrder(ctx context.Context, in *api.CreateOrderRequest) (*api.CreateOrderResponse, error) {
-	log := logger.GetLoggerFromCtx(ctx)
+	log := logger.GetLoggerFromCtx(ctx).Named("transport")
 
 	log.Debug(ctx, "CreateOrder raw request",
-		zap.Any("raw request", in),
+		log.Payload("raw request", in),
 	)
 
 	log.Info(ctx, "CreateOrder started",
@@ -67,7 +71,7 @@ func (s *OrderServer) CreateOrder(ctx context.Context, in *api.CreateOrderReques
 			zap.Int32("quantity", in.GetQuantity()),
 			zap.Error(err),
 		)
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, statusError(err)
 	}
 
 	log.Info(ctx, "CreateOrder completed",
@@ -81,17 +85,17 @@ func (s *OrderServer) CreateOrder(ctx context.Context, in *api.CreateOrderReques
 	}
 
 	log.Debug(ctx, "CreateOrder raw response",
-		zap.Any("raw response", resp),
+		log.Payload("raw response", resp),
 	)
 
 	return resp, nil
 }
 
 func (s *OrderServer) GetOrder(ctx context.Context, in *api.GetOrderRequest) (*api.GetOrderResponse, error) {
-	log := logger.GetLoggerFromCtx(ctx)
+	log := logger.GetLoggerFromCtx(ctx).Named("transport")
 
 	log.Debug(ctx, "GetOrder raw request",
-		zap.Any("raw request", in),
+		log.Payload("raw request", in),
 	)
 
 	log.Info(ctx, "GetOrder started",
@@ -100,11 +104,19 @@ func (s *OrderServer) GetOrder(ctx context.Context, in *api.GetOrderRequest) (*a
 
 	order, err := s.service.GetOrder(ctx, in.GetId())
 	if err != nil {
-		log.Warn(ctx, "GetOrder not found",
+		if errors.Is(err, database.ErrNotFound) {
+			log.Warn(ctx, "GetOrder not found",
+				zap.String("order_id", in.GetId()),
+				zap.Error(err),
+			)
+			return nil, statusError(err)
+		}
+
+		log.Error(ctx, "GetOrder failed",
 			zap.String("order_id", in.GetId()),
 			zap.Error(err),
 		)
-		return nil, status.Error(codes.NotFound, err.Error())
+		return nil, statusError(err)
 	}
 
 	log.Info(ctx, "GetOrder completed",
@@ -118,17 +130,17 @@ func (s *OrderServer) GetOrder(ctx context.Context, in *api.GetOrderRequest) (*a
 	}
 
 	log.Debug(ctx, "GetOrder raw response",
-		zap.Any("raw response", resp),
+		log.Payload("raw response", resp),
 	)
 
 	return resp, nil
 }
 
 func (s *OrderServer) UpdateOrder(ctx context.Context, in *api.UpdateOrderRequest) (*api.UpdateOrderResponse, error) {
-	log := logger.GetLoggerFromCtx(ctx)
+	log := logger.GetLoggerFromCtx(ctx).Named("transport")
 
 	log.Debug(ctx, "UpdateOrder raw request",
-		zap.Any("raw request", in),
+		log.Payload("raw request", in),
 	)
 
 	log.Info(ctx, "UpdateOrder started",
@@ -139,19 +151,19 @@ func (s *OrderServer) UpdateOrder(ctx context.Context, in *api.UpdateOrderReques
 
 	updOrder, err := s.service.UpdateOrder(ctx, in.GetId(), in.GetItem(), in.GetQuantity())
 	if err != nil {
-		if strings.Contains(err.Error(), "does not exists") {
+		if errors.Is(err, database.ErrNotFound) {
 			log.Warn(ctx, "UpdateOrder not found",
 				zap.String("order_id", in.GetId()),
 				zap.Error(err),
 			)
-			return nil, status.Error(codes.NotFound, err.Error())
+			return nil, statusError(err)
 		}
 
 		log.Error(ctx, "UpdateOrder failed",
 			zap.String("order_id", in.GetId()),
 			zap.Error(err),
 		)
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, statusError(err)
 	}
 
 	log.Info(ctx, "UpdateOrder completed",
@@ -165,17 +177,17 @@ func (s *OrderServer) UpdateOrder(ctx context.Context, in *api.UpdateOrderReques
 	}
 
 	log.Debug(ctx, "UpdateOrder raw response",
-		zap.Any("raw response", resp),
+		log.Payload("raw response", resp),
 	)
 
 	return resp, nil
 }
 
 func (s *OrderServer) DeleteOrder(ctx context.Context, in *api.DeleteOrderRequest) (*api.DeleteOrderResponse, error) {
-	log := logger.GetLoggerFromCtx(ctx)
+	log := logger.GetLoggerFromCtx(ctx).Named("transport")
 
 	log.Debug(ctx, "DeleteOrder raw request",
-		zap.Any("raw request", in),
+		log.Payload("raw request", in),
 	)
 
 	log.Info(ctx, "DeleteOrder started",
@@ -184,11 +196,19 @@ func (s *OrderServer) DeleteOrder(ctx context.Context, in *api.DeleteOrderReques
 
 	success, err := s.service.DeleteOrder(ctx, in.GetId())
 	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			log.Warn(ctx, "DeleteOrder not found",
+				zap.String("order_id", in.GetId()),
+				zap.Error(err),
+			)
+			return nil, statusError(err)
+		}
+
 		log.Error(ctx, "DeleteOrder failed",
 			zap.String("order_id", in.GetId()),
 			zap.Error(err),
 		)
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, statusError(err)
 	}
 
 	if success {
@@ -208,17 +228,17 @@ func (s *OrderServer) DeleteOrder(ctx context.Context, in *api.DeleteOrderReques
 	}
 
 	log.Debug(ctx, "DeleteOrder raw response",
-		zap.Any("raw response", resp),
+		log.Payload("raw response", resp),
 	)
 
 	return resp, nil
 }
 
 func (s *OrderServer) ListOrders(ctx context.Context, in *api.ListOrdersRequest) (*api.ListOrdersResponse, error) {
-	log := logger.GetLoggerFromCtx(ctx)
+	log := logger.GetLoggerFromCtx(ctx).Named("transport")
 
 	log.Debug(ctx, "ListOrder raw request",
-		zap.Any("raw request", in),
+		log.Payload("raw request", in),
 	)
 
 	log.Info(ctx, "ListOrders started")
@@ -228,7 +248,7 @@ func (s *OrderServer) ListOrders(ctx context.Context, in *api.ListOrdersRequest)
 		log.Error(ctx, "ListOrders failed",
 			zap.Error(err),
 		)
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, statusError(err)
 	}
 
 	log.Info(ctx, "ListOrders completed",
@@ -240,8 +260,34 @@ func (s *OrderServer) ListOrders(ctx context.Context, in *api.ListOrdersRequest)
 	}
 
 	log.Debug(ctx, "ListOrder raw response",
-		zap.Any("raw response", resp),
+		log.Payload("raw response", resp),
 	)
 
 	return resp, nil
 }
+
+// statusError maps a service error to a status with a fixed, client-safe
+// message; the wrapped chain names internals and is only logged by callers.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, patterns.ErrBulkheadFull):
+		return status.Error(codes.ResourceExhausted, "too many concurrent requests, retry later")
+	case errors.Is(err, database.ErrNotFound):
+		return status.Error(codes.NotFound, "order not found")
+	}
+
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		st, detailsErr := status.New(codes.InvalidArgument, validationErr.Reason).WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: validationErr.Field, Description: validationErr.Reason},
+			},
+		})
+		if detailsErr != nil {
+			return status.Error(codes.InvalidArgument, validationErr.Reason)
+		}
+		return st.Err()
+	}
+
+	return status.Error(codes.Internal, "internal error")
+}
@@ -2,15 +2,19 @@ package transport_test
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/repository/database"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/service"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
 	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
 	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type MockOrderService struct {
@@ -69,9 +73,9 @@ func TestOrderServer_CreateOrder_ValidationError(t *testing.T) {
 	server := transport.NewOrderServer(mockService)
 
 	mockService.On("CreateOrder", mock.Anything, "", int32(2)).
-		Return("", errors.New("item cannot be empty"))
+		Return("", &service.ValidationError{Field: "item", Reason: "item cannot be empty"})
 	mockService.On("CreateOrder", mock.Anything, "laptop", int32(0)).
-		Return("", errors.New("quantity must be positive"))
+		Return("", &service.ValidationError{Field: "quantity", Reason: "quantity must be positive"})
 
 	ctx, _ := logger.New(context.Background(), "")
 
@@ -114,7 +118,7 @@ func TestOrderServer_GetOrder_NotFound(t *testing.T) {
 	server := transport.NewOrderServer(mockService)
 
 	mockService.On("GetOrder", mock.Anything, "999").
-		Return((*api.Order)(nil), errors.New("not found"))
+		Return((*api.Order)(nil), fmt.Errorf("database: select order 999: %w", database.ErrNotFound))
 
 	ctx, _ := logger.New(context.Background(), "")
 
@@ -123,9 +127,38 @@ func TestOrderServer_GetOrder_NotFound(t *testing.T) {
 
 	require.Error(t, err)
 	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "order not found", status.Convert(err).Message(), "wrapped chain stays in logs")
 	mockService.AssertExpectations(t)
 }
 
+func TestOrderServer_GetOrder_InternalError(t *testing.T) {
+	mockService := new(MockOrderService)
+	server := transport.NewOrderServer(mockService)
+
+	mockService.On("GetOrder", mock.Anything, "1").
+		Return((*api.Order)(nil), fmt.Errorf("database: select: %w", context.DeadlineExceeded))
+
+	ctx, _ := logger.New(context.Background(), "")
+
+	_, err := server.GetOrder(ctx, &api.GetOrderRequest{Id: "1"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, err.Error(), "database")
+}
+
+func TestOrderServer_UpdateOrder_NotFound(t *testing.T) {
+	mockService := new(MockOrderService)
+	server := transport.NewOrderServer(mockService)
+
+	mockService.On("UpdateOrder", mock.Anything, "999", "laptop", int32(1)).
+		Return((*api.Order)(nil), fmt.Errorf("database: update order 999: %w", database.ErrNotFound))
+
+	ctx, _ := logger.New(context.Background(), "")
+
+	_, err := server.UpdateOrder(ctx, &api.UpdateOrderRequest{Id: "999", Item: "laptop", Quantity: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
 func TestOrderServer_UpdateOrder_Success(t *testing.T) {
 	mockService := new(MockOrderService)
 	server := transport.NewOrderServer(mockService)
@@ -149,9 +182,9 @@ func TestOrderServer_UpdateOrder_ValidationError(t *testing.T) {
 	server := transport.NewOrderServer(mockService)
 
 	mockService.On("UpdateOrder", mock.Anything, "123", "", int32(3)).
-		Return((*api.Order)(nil), errors.New("item cannot be empty"))
+		Return((*api.Order)(nil), &service.ValidationError{Field: "item", Reason: "item cannot be empty"})
 	mockService.On("UpdateOrder", mock.Anything, "123", "laptop", int32(0)).
-		Return((*api.Order)(nil), errors.New("quantity must be positive"))
+		Return((*api.Order)(nil), &service.ValidationError{Field: "quantity", Reason: "quantity must be positive"})
 
 	ctx, _ := logger.New(context.Background(), "")
 
@@ -192,7 +225,8 @@ func TestOrderServer_DeleteOrder_NotFound(t *testing.T) {
 	mockService := new(MockOrderService)
 	server := transport.NewOrderServer(mockService)
 
-	mockService.On("DeleteOrder", mock.Anything, "999").Return(false, errors.New("not found"))
+	mockService.On("DeleteOrder", mock.Anything, "999").
+		Return(false, fmt.Errorf("database: delete order 999: %w", database.ErrNotFound))
 
 	ctx, _ := logger.New(context.Background(), "")
 
@@ -201,6 +235,7 @@ func TestOrderServer_DeleteOrder_NotFound(t *testing.T) {
 
 	require.Error(t, err)
 	assert.False(t, resp.GetSuccess())
+	assert.Equal(t, codes.NotFound, status.Code(err))
 	mockService.AssertExpectations(t)
 }
 
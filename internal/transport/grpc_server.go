@@ -0,0 +1,91 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCServerCfg holds transport-level limits of the gRPC server. Zero
+// connection idle/age durations mean no limit, as in grpc-go.
+type GRPCServerCfg struct {
+	MaxRecvMsgSize             int           `env:"GRPC_MAX_RECV_MSG_SIZE"               env-default:"4194304"`
+	MaxSendMsgSize             int           `env:"GRPC_MAX_SEND_MSG_SIZE"               env-default:"4194304"`
+	MaxConcurrentStreams       uint32        `env:"GRPC_MAX_CONCURRENT_STREAMS"          env-default:"1000"`
+	KeepaliveTime              time.Duration `env:"GRPC_KEEPALIVE_TIME"                  env-default:"2h"`
+	KeepaliveTimeout           time.Duration `env:"GRPC_KEEPALIVE_TIMEOUT"               env-default:"20s"`
+	KeepaliveMinTime           time.Duration `env:"GRPC_KEEPALIVE_MIN_TIME"              env-default:"5m"`
+	KeepalivePermitWithoutCall bool          `env:"GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM" env-default:"false"`
+	MaxConnectionIdle          time.Duration `env:"GRPC_MAX_CONNECTION_IDLE"             env-default:"0s"`
+	MaxConnectionAge           time.Duration `env:"GRPC_MAX_CONNECTION_AGE"              env-default:"0s"`
+	MaxConnectionAgeGrace      time.Duration `env:"GRPC_MAX_CONNECTION_AGE_GRACE"        env-default:"0s"`
+}
+
+func ServerOptions(cfg GRPCServerCfg) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(cfg.MaxSendMsgSize),
+		grpc.MaxConcurrentStreams(cfg.MaxConcurrentStreams),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     cfg.MaxConnectionIdle,
+			MaxConnectionAge:      cfg.MaxConnectionAge,
+			MaxConnectionAgeGrace: cfg.MaxConnectionAgeGrace,
+			Time:                  cfg.KeepaliveTime,
+			Timeout:               cfg.KeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.KeepaliveMinTime,
+			PermitWithoutStream: cfg.KeepalivePermitWithoutCall,
+		}),
+	}
+}
+
+// RecoveryInterceptor turns a panic in a handler into an INTERNAL error. It
+// must be the first interceptor in the chain so panics in the others are
+// caught too, which is why it logs with the root logger.
+func RecoveryInterceptor(rootCtx context.Context) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(rootCtx, info.FullMethod, r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func RecoveryStreamInterceptor(rootCtx context.Context) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(rootCtx, info.FullMethod, r)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, method string, r any) error {
+	logger.GetLoggerFromCtx(ctx).Named("transport").Error(ctx, "panic in gRPC handler",
+		zap.String("method", method),
+		zap.String("panic", fmt.Sprint(r)),
+		zap.ByteString("stack", debug.Stack()),
+	)
+
+	return status.Error(codes.Internal, "internal server error")
+}
@@ -0,0 +1,99 @@
+package transport_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger/loggertest"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func startGRPCServer(t *testing.T, ctx context.Context, cfg transport.GRPCServerCfg, service transport.OrderService) api.OrderServiceClient {
+	t.Helper()
+
+	opts := append(transport.ServerOptions(cfg),
+		grpc.ChainUnaryInterceptor(transport.RecoveryInterceptor(ctx), logger.LoggerInterceptor(ctx)),
+	)
+	server := grpc.NewServer(opts...)
+	api.RegisterOrderServiceServer(server, transport.NewOrderServer(service))
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return api.NewOrderServiceClient(conn)
+}
+
+func defaultGRPCServerCfg() transport.GRPCServerCfg {
+	return transport.GRPCServerCfg{
+		MaxRecvMsgSize:       1024,
+		MaxSendMsgSize:       1024,
+		MaxConcurrentStreams: 10,
+	}
+}
+
+func TestRecoveryInterceptor(t *testing.T) {
+	ctx, logs := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+
+	mockService := new(MockOrderService)
+	mockService.On("GetOrder", mock.Anything, "1").Run(func(mock.Arguments) {
+		panic("boom")
+	})
+	mockService.On("GetOrder", mock.Anything, "2").Return(&api.Order{Id: "2"}, nil)
+
+	client := startGRPCServer(t, ctx, defaultGRPCServerCfg(), mockService)
+
+	_, err := client.GetOrder(ctx, &api.GetOrderRequest{Id: "1"})
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, status.Convert(err).Message(), "boom")
+
+	panics := logs.FilterMessage("panic in gRPC handler").All()
+	require.Len(t, panics, 1)
+	assert.Equal(t, "boom", panics[0].ContextMap()["panic"])
+
+	resp, err := client.GetOrder(ctx, &api.GetOrderRequest{Id: "2"})
+	require.NoError(t, err, "server keeps serving after a panic")
+	assert.Equal(t, "2", resp.GetOrder().GetId())
+}
+
+func TestServerOptions_MessageSize(t *testing.T) {
+	ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+
+	mockService := new(MockOrderService)
+	mockService.On("GetOrder", mock.Anything, "small").Return(&api.Order{Id: "small"}, nil)
+	mockService.On("GetOrder", mock.Anything, "large").Return(&api.Order{Id: "large", Item: strings.Repeat("x", 2048)}, nil)
+
+	client := startGRPCServer(t, ctx, defaultGRPCServerCfg(), mockService)
+
+	_, err := client.GetOrder(ctx, &api.GetOrderRequest{Id: "small"})
+	require.NoError(t, err)
+
+	_, err = client.GetOrder(ctx, &api.GetOrderRequest{Id: strings.Repeat("x", 2048)})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "request above MaxRecvMsgSize")
+
+	_, err = client.GetOrder(ctx, &api.GetOrderRequest{Id: "large"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "response above MaxSendMsgSize")
+}
@@ -0,0 +1,196 @@
+package transport_test
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger/loggertest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func largeBody() string {
+	return strings.Repeat(`{"id":"1","item":"laptop","quantity":1},`, 100)
+}
+
+func gatewayHandler(t *testing.T, cfg transport.GatewayCfg, h http.Handler) (http.Handler, *observer.ObservedLogs) {
+	t.Helper()
+
+	ctx, logs := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+	return transport.GatewayMiddleware(logger.GetLoggerFromCtx(ctx), cfg, h), logs
+}
+
+func writeBody(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-1")
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+func TestGatewayMiddleware_Compression(t *testing.T) {
+	cfg := transport.GatewayCfg{CompressionEnabled: true, CompressionMinSize: 1024}
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		body           string
+		wantEncoding   string
+		decode         func(io.Reader) (io.Reader, error)
+	}{
+		{
+			name:           "brotli preferred",
+			acceptEncoding: "gzip, deflate, br",
+			body:           largeBody(),
+			wantEncoding:   "br",
+			decode:         func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+		},
+		{
+			name:           "gzip when br excluded",
+			acceptEncoding: "gzip, br;q=0",
+			body:           largeBody(),
+			wantEncoding:   "gzip",
+			decode:         func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		},
+		{
+			name:           "small body stays plain",
+			acceptEncoding: "gzip",
+			body:           `{"id":"1"}`,
+		},
+		{
+			name: "no accept-encoding",
+			body: largeBody(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := gatewayHandler(t, cfg, writeBody(tt.body))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.wantEncoding, rec.Header().Get("Content-Encoding"))
+			assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+
+			var body io.Reader = rec.Body
+			if tt.decode != nil {
+				var err error
+				body, err = tt.decode(rec.Body)
+				require.NoError(t, err)
+			}
+			data, err := io.ReadAll(body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, string(data))
+		})
+	}
+}
+
+func TestGatewayMiddleware_CORS(t *testing.T) {
+	cfg := transport.GatewayCfg{
+		CORSAllowedOrigins: []string{"https://app.example.com"},
+		CORSAllowedMethods: []string{"GET", "POST"},
+		CORSAllowedHeaders: []string{"Authorization", "Content-Type"},
+		CORSExposedHeaders: []string{"X-Request-Id"},
+		CORSMaxAge:         time.Minute,
+	}
+	h, _ := gatewayHandler(t, cfg, writeBody("{}"))
+
+	t.Run("preflight", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/orders", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Authorization, Content-Type", rec.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "60", rec.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("simple request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "X-Request-Id", rec.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("unknown origin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/orders", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestGatewayMiddleware_BodyLimit(t *testing.T) {
+	cfg := transport.GatewayCfg{MaxBodyBytes: 16}
+	h, _ := gatewayHandler(t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(largeBody())))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(largeBody()))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code, "streamed body is limited too")
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestGatewayMiddleware_SecurityHeadersAndAccessLog(t *testing.T) {
+	cfg := transport.GatewayCfg{SecurityHeaders: true, AccessLog: true}
+	h, logs := gatewayHandler(t, cfg, writeBody("{}"))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil))
+
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+	assert.Contains(t, rec.Header().Get("Content-Security-Policy"), "default-src 'none'")
+
+	entries := logs.FilterMessage("http request").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "GET", fields["method"])
+	assert.Equal(t, "/api/v1/orders", fields["path"])
+	assert.EqualValues(t, http.StatusOK, fields["status"])
+	assert.EqualValues(t, 2, fields["bytes"])
+	assert.Equal(t, "req-1", fields["request_id"])
+	assert.Equal(t, "gateway", entries[0].LoggerName)
+}
@@ -0,0 +1,44 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+	"strings"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+const (
+	SpecPath = "/openapi.json"
+	DocsPath = "/docs/"
+)
+
+// order.swagger.json is generated from api/order.proto, see `make openapi`.
+//
+//go:embed order.swagger.json
+var spec []byte
+
+//go:embed swagger-initializer.js
+var initializer []byte
+
+func SpecHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(spec)
+	})
+}
+
+// DocsHandler serves Swagger UI under DocsPath, pointed at SpecPath.
+func DocsHandler() http.Handler {
+	files := http.StripPrefix(DocsPath, http.FileServer(http.FS(swaggerFiles.FS)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.URL.Path, DocsPath) == "swagger-initializer.js" {
+			w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+			_, _ = w.Write(initializer)
+			return
+		}
+
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,56 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport/openapi"
+)
+
+func serve(h http.Handler, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestSpecHandler(t *testing.T) {
+	rec := serve(openapi.SpecHandler(), openapi.SpecPath)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var spec struct {
+		Swagger     string                     `json:"swagger"`
+		Paths       map[string]json.RawMessage `json:"paths"`
+		Definitions map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"definitions"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+	assert.Equal(t, "2.0", spec.Swagger)
+	assert.Contains(t, spec.Paths, "/api/v1/orders")
+	assert.Contains(t, spec.Paths, "/api/v1/orders/{id}")
+
+	require.Contains(t, spec.Definitions, "apiProblem", "error responses are documented as problem+json")
+	assert.Contains(t, spec.Definitions["apiProblem"].Properties, "invalid_params")
+	assert.Contains(t, spec.Definitions["apiProblem"].Properties, "request_id")
+	assert.NotContains(t, spec.Definitions, "rpcStatus")
+}
+
+func TestDocsHandler(t *testing.T) {
+	h := openapi.DocsHandler()
+
+	rec := serve(h, openapi.DocsPath)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "swagger-ui")
+
+	rec = serve(h, openapi.DocsPath+"swagger-initializer.js")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `url: "/openapi.json"`)
+
+	rec = serve(h, openapi.DocsPath+"swagger-ui-bundle.js")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
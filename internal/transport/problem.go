@@ -0,0 +1,103 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	internalErrorText  = "internal server error"
+)
+
+// problem is an RFC 7807 problem details document.
+type problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	Code          string         `json:"code"`
+	RequestID     string         `json:"request_id,omitempty"`
+	InvalidParams []invalidParam `json:"invalid_params,omitempty"`
+}
+
+type invalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ProblemErrorHandler writes gRPC errors as application/problem+json. The
+// text of 5xx errors is replaced with a generic message and only logged.
+func ProblemErrorHandler(log *logger.Logger) runtime.ErrorHandlerFunc {
+	return func(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		st := status.Convert(err)
+		httpStatus := runtime.HTTPStatusFromCode(st.Code())
+
+		md, _ := runtime.ServerMetadataFromContext(ctx)
+		for k, vs := range md.HeaderMD {
+			if h, ok := outgoingHeaderMatcher(k); ok {
+				for _, v := range vs {
+					w.Header().Add(h, v)
+				}
+			}
+		}
+
+		requestID := r.Header.Get("X-Request-Id")
+		if ids := md.HeaderMD.Get(logger.RequestIDHeader); len(ids) > 0 {
+			requestID = ids[0]
+		}
+
+		p := problem{
+			Type:      "about:blank",
+			Title:     http.StatusText(httpStatus),
+			Status:    httpStatus,
+			Detail:    st.Message(),
+			Instance:  r.URL.Path,
+			Code:      st.Code().String(),
+			RequestID: requestID,
+		}
+
+		if httpStatus >= http.StatusInternalServerError {
+			log.Named("gateway").Error(ctx, "request failed",
+				zap.String("request_id", requestID),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("code", st.Code().String()),
+				zap.String("error", st.Message()),
+			)
+			p.Detail = internalErrorText
+		} else {
+			p.InvalidParams = fieldViolations(st)
+		}
+
+		w.Header().Del("Trailer")
+		w.Header().Del("Transfer-Encoding")
+		w.Header().Set("Content-Type", problemContentType)
+		w.WriteHeader(httpStatus)
+		_ = json.NewEncoder(w).Encode(p)
+	}
+}
+
+func fieldViolations(st *status.Status) []invalidParam {
+	var params []invalidParam
+	for _, detail := range st.Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+
+		for _, v := range badRequest.GetFieldViolations() {
+			params = append(params, invalidParam{Name: v.GetField(), Reason: v.GetDescription()})
+		}
+	}
+
+	return params
+}
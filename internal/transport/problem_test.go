@@ -0,0 +1,111 @@
+package transport_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/service"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger/loggertest"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type problemBody struct {
+	Title         string `json:"title"`
+	Status        int    `json:"status"`
+	Detail        string `json:"detail"`
+	Instance      string `json:"instance"`
+	Code          string `json:"code"`
+	RequestID     string `json:"request_id"`
+	InvalidParams []struct {
+		Name   string `json:"name"`
+		Reason string `json:"reason"`
+	} `json:"invalid_params"`
+}
+
+func handleError(t *testing.T, ctx context.Context, md metadata.MD, err error) (*httptest.ResponseRecorder, problemBody) {
+	t.Helper()
+
+	log := logger.GetLoggerFromCtx(ctx)
+	ctx = runtime.NewServerMetadataContext(ctx, runtime.ServerMetadata{HeaderMD: md})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", nil)
+	transport.ProblemErrorHandler(log)(ctx, nil, nil, rec, req, err)
+
+	var body problemBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+
+	return rec, body
+}
+
+func TestProblemErrorHandler_FieldViolations(t *testing.T) {
+	mockService := new(MockOrderService)
+	mockService.On("CreateOrder", mock.Anything, "", int32(1)).
+		Return("", &service.ValidationError{Field: "item", Reason: "item cannot be empty"})
+
+	ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+	_, grpcErr := transport.NewOrderServer(mockService).CreateOrder(ctx, &api.CreateOrderRequest{Quantity: 1})
+	require.Equal(t, codes.InvalidArgument, status.Code(grpcErr))
+
+	rec, body := handleError(t, ctx, metadata.Pairs(logger.RequestIDHeader, "req-1"), grpcErr)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "req-1", rec.Header().Get("X-Request-Id"))
+	assert.Equal(t, "Bad Request", body.Title)
+	assert.Equal(t, "InvalidArgument", body.Code)
+	assert.Equal(t, "/api/v1/orders", body.Instance)
+	assert.Equal(t, "req-1", body.RequestID)
+	require.Len(t, body.InvalidParams, 1)
+	assert.Equal(t, "item", body.InvalidParams[0].Name)
+	assert.Equal(t, "item cannot be empty", body.InvalidParams[0].Reason)
+}
+
+func TestProblemErrorHandler_HidesInternalErrors(t *testing.T) {
+	mockService := new(MockOrderService)
+	mockService.On("CreateOrder", mock.Anything, "laptop", int32(1)).
+		Return("", errors.New("database: insert: connection refused"))
+
+	ctx, logs := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+	_, grpcErr := transport.NewOrderServer(mockService).CreateOrder(ctx, &api.CreateOrderRequest{Item: "laptop", Quantity: 1})
+	require.Equal(t, codes.Internal, status.Code(grpcErr))
+
+	rec, body := handleError(t, ctx, metadata.Pairs(logger.RequestIDHeader, "req-2"), grpcErr)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "database")
+	assert.Equal(t, "internal server error", body.Detail)
+
+	entries := logs.FilterMessage("request failed").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "req-2", entries[0].ContextMap()["request_id"])
+
+	failed := logs.FilterMessage("CreateOrder failed").All()
+	require.Len(t, failed, 1)
+	assert.Contains(t, failed[0].ContextMap()["error"], "connection refused", "the cause is logged by the handler")
+}
+
+func TestProblemErrorHandler_ForwardsHeaders(t *testing.T) {
+	ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+
+	rec, body := handleError(t, ctx, metadata.Pairs("retry-after", "2"),
+		status.Error(codes.ResourceExhausted, "rate limit exceeded"))
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.Equal(t, "rate limit exceeded", body.Detail)
+}
@@ -0,0 +1,233 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/auth"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	RateLimitBackendLocal = "local"
+	RateLimitBackendRedis = "redis"
+
+	RateLimitKeyIdentity = "identity"
+	RateLimitKeyIP       = "ip"
+	RateLimitKeyMethod   = "method"
+
+	retryAfterHeader = "retry-after"
+	forwardedHeader  = "x-forwarded-for"
+)
+
+type RateLimitCfg struct {
+	Enabled bool     `env:"RATE_LIMIT_ENABLED" env-default:"true"`
+	Backend string   `env:"RATE_LIMIT_BACKEND" env-default:"local"`
+	Rate    float64  `env:"RATE_LIMIT_RPS"     env-default:"50"`
+	Burst   int      `env:"RATE_LIMIT_BURST"   env-default:"100"`
+	KeyBy   []string `env:"RATE_LIMIT_KEY_BY"  env-default:"identity,ip,method" env-separator:","`
+	// TrustedProxies lists the addresses or CIDRs whose x-forwarded-for
+	// entries are believed, e.g. the loopback range for the dial-mode gateway.
+	TrustedProxies []string `env:"RATE_LIMIT_TRUSTED_PROXIES" env-separator:","`
+}
+
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (bool, time.Duration, error)
+}
+
+// ParseTrustedProxies parses addresses and CIDRs, a bare address trusts
+// only itself.
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+func RateLimitInterceptor(
+	limiter RateLimiter,
+	keyBy []string,
+	trustedProxies []netip.Prefix,
+) grpc.UnaryServerInterceptor {
+	keys := rateLimitKeys{keyBy: keyBy, trustedProxies: trustedProxies}
+
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if retryAfter, limited := rateLimited(ctx, limiter, keys, info.FullMethod); limited {
+			return nil, rateLimitExceeded(ctx, info.FullMethod, retryAfter, func(md metadata.MD) error {
+				return grpc.SetHeader(ctx, md)
+			})
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor charges one token per stream, not per message.
+func RateLimitStreamInterceptor(
+	limiter RateLimiter,
+	keyBy []string,
+	trustedProxies []netip.Prefix,
+) grpc.StreamServerInterceptor {
+	keys := rateLimitKeys{keyBy: keyBy, trustedProxies: trustedProxies}
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if retryAfter, limited := rateLimited(ss.Context(), limiter, keys, info.FullMethod); limited {
+			return rateLimitExceeded(ss.Context(), info.FullMethod, retryAfter, ss.SetHeader)
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// rateLimited fails open: a broken limiter backend must not take the API down.
+func rateLimited(ctx context.Context, limiter RateLimiter, keys rateLimitKeys, method string) (time.Duration, bool) {
+	allowed, retryAfter, err := limiter.Allow(ctx, keys.key(ctx, method))
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Named("transport").Error(ctx, "rate limiter failed, request allowed",
+			zap.String("method", method),
+			zap.Error(err),
+		)
+		return 0, false
+	}
+
+	return retryAfter, !allowed
+}
+
+func rateLimitExceeded(
+	ctx context.Context,
+	method string,
+	retryAfter time.Duration,
+	setHeader func(metadata.MD) error,
+) error {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	if err := setHeader(metadata.Pairs(retryAfterHeader, strconv.FormatInt(max(seconds, 1), 10))); err != nil {
+		logger.GetLoggerFromCtx(ctx).Named("transport").Warn(ctx, "failed to set retry-after header", zap.Error(err))
+	}
+
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded for "+method)
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
+
+type rateLimitKeys struct {
+	keyBy          []string
+	trustedProxies []netip.Prefix
+}
+
+func (k rateLimitKeys) key(ctx context.Context, method string) string {
+	parts := make([]string, 0, len(k.keyBy))
+
+	for _, key := range k.keyBy {
+		switch strings.TrimSpace(key) {
+		case RateLimitKeyIdentity:
+			parts = append(parts, "id="+k.callerIdentity(ctx))
+		case RateLimitKeyIP:
+			parts = append(parts, "ip="+k.callerIP(ctx))
+		case RateLimitKeyMethod:
+			parts = append(parts, "m="+method)
+		}
+	}
+
+	return strings.Join(parts, "|")
+}
+
+// callerIdentity trusts only the authenticated subject; anything the client
+// sends itself could be rotated per request to get a fresh bucket, so
+// anonymous callers are told apart by address instead.
+func (k rateLimitKeys) callerIdentity(ctx context.Context) string {
+	if p, ok := auth.PrincipalFromCtx(ctx); ok {
+		return p.Subject
+	}
+
+	return "anonymous@" + k.callerIP(ctx)
+}
+
+// callerIP walks x-forwarded-for from the right and stops at the first hop
+// that is not a trusted proxy, so entries a client wrote itself are never
+// used. The in-process gateway is part of this server: the entry it appends
+// for its HTTP peer is taken as the starting point.
+func (k rateLimitKeys) callerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+
+	addr, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		addr = p.Addr.String()
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var hops []string
+	for _, value := range md.Get(forwardedHeader) {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+
+	if p.Addr.Network() == inProcessNetwork && len(hops) > 0 {
+		addr, hops = hops[len(hops)-1], hops[:len(hops)-1]
+	}
+	for len(hops) > 0 && k.trusted(addr) {
+		addr, hops = hops[len(hops)-1], hops[:len(hops)-1]
+	}
+
+	return addr
+}
+
+func (k rateLimitKeys) trusted(addr string) bool {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+
+	return slices.ContainsFunc(k.trustedProxies, func(prefix netip.Prefix) bool {
+		return prefix.Contains(ip)
+	})
+}
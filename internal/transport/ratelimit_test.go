@@ -0,0 +1,153 @@
+package transport_test
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/patterns"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func rateLimitCtx(t *testing.T, ip string, md metadata.MD) context.Context {
+	t.Helper()
+
+	ctx, err := logger.New(context.Background(), "")
+	require.NoError(t, err)
+
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 40000}})
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.OrderService/CreateOrder"}
+	keyBy := []string{transport.RateLimitKeyIdentity, transport.RateLimitKeyIP, transport.RateLimitKeyMethod}
+
+	t.Run("rejects requests over limit", func(t *testing.T) {
+		interceptor := transport.RateLimitInterceptor(patterns.NewTokenBucketLimiter(1, 2), keyBy, nil)
+		ctx := rateLimitCtx(t, "10.0.0.1", metadata.MD{})
+
+		for range 2 {
+			resp, err := interceptor(ctx, nil, info, handler)
+			require.NoError(t, err)
+			assert.Equal(t, "ok", resp)
+		}
+
+		resp, err := interceptor(ctx, nil, info, handler)
+		require.Error(t, err)
+		assert.Nil(t, resp)
+
+		st := status.Convert(err)
+		assert.Equal(t, codes.ResourceExhausted, st.Code())
+		require.Len(t, st.Details(), 1)
+		assert.IsType(t, &errdetails.RetryInfo{}, st.Details()[0])
+	})
+
+	t.Run("separate buckets per caller ip", func(t *testing.T) {
+		interceptor := transport.RateLimitInterceptor(patterns.NewTokenBucketLimiter(1, 1), keyBy, nil)
+
+		_, err := interceptor(rateLimitCtx(t, "10.0.0.1", metadata.MD{}), nil, info, handler)
+		require.NoError(t, err)
+
+		_, err = interceptor(rateLimitCtx(t, "10.0.0.2", metadata.MD{}), nil, info, handler)
+		require.NoError(t, err)
+
+		_, err = interceptor(rateLimitCtx(t, "10.0.0.1", metadata.MD{}), nil, info, handler)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("ignores client supplied identity", func(t *testing.T) {
+		interceptor := transport.RateLimitInterceptor(patterns.NewTokenBucketLimiter(1, 1),
+			[]string{transport.RateLimitKeyIdentity}, nil)
+
+		_, err := interceptor(rateLimitCtx(t, "10.0.0.1", metadata.Pairs("x-client-id", "a")), nil, info, handler)
+		require.NoError(t, err)
+
+		_, err = interceptor(rateLimitCtx(t, "10.0.0.1", metadata.Pairs("x-client-id", "b")), nil, info, handler)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		_, err = interceptor(rateLimitCtx(t, "10.0.0.2", metadata.MD{}), nil, info, handler)
+		require.NoError(t, err, "anonymous callers are keyed by address")
+	})
+
+	t.Run("uses forwarded address behind trusted proxy", func(t *testing.T) {
+		trusted, err := transport.ParseTrustedProxies([]string{"127.0.0.0/8"})
+		require.NoError(t, err)
+		interceptor := transport.RateLimitInterceptor(patterns.NewTokenBucketLimiter(1, 1), keyBy, trusted)
+
+		first := metadata.Pairs("x-forwarded-for", "203.0.113.7")
+		second := metadata.Pairs("x-forwarded-for", "203.0.113.8")
+
+		_, err = interceptor(rateLimitCtx(t, "127.0.0.1", first), nil, info, handler)
+		require.NoError(t, err)
+
+		_, err = interceptor(rateLimitCtx(t, "127.0.0.1", second), nil, info, handler)
+		require.NoError(t, err)
+
+		_, err = interceptor(rateLimitCtx(t, "127.0.0.1", first), nil, info, handler)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("ignores forwarded address from untrusted peer", func(t *testing.T) {
+		interceptor := transport.RateLimitInterceptor(patterns.NewTokenBucketLimiter(1, 1), keyBy, nil)
+
+		_, err := interceptor(rateLimitCtx(t, "127.0.0.1", metadata.Pairs("x-forwarded-for", "203.0.113.7")),
+			nil, info, handler)
+		require.NoError(t, err)
+
+		_, err = interceptor(rateLimitCtx(t, "127.0.0.1", metadata.Pairs("x-forwarded-for", "203.0.113.8")),
+			nil, info, handler)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("skips only trusted hops", func(t *testing.T) {
+		trusted, err := transport.ParseTrustedProxies([]string{"10.0.0.0/8"})
+		require.NoError(t, err)
+		interceptor := transport.RateLimitInterceptor(patterns.NewTokenBucketLimiter(1, 1), keyBy, trusted)
+
+		spoofed := func(fake string) metadata.MD {
+			return metadata.Pairs("x-forwarded-for", fake+", 203.0.113.7, 10.1.1.1")
+		}
+
+		_, err = interceptor(rateLimitCtx(t, "10.0.0.1", spoofed("198.51.100.1")), nil, info, handler)
+		require.NoError(t, err)
+
+		_, err = interceptor(rateLimitCtx(t, "10.0.0.1", spoofed("198.51.100.2")), nil, info, handler)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("separate buckets per method", func(t *testing.T) {
+		interceptor := transport.RateLimitInterceptor(patterns.NewTokenBucketLimiter(1, 1), keyBy, nil)
+		ctx := rateLimitCtx(t, "10.0.0.1", metadata.MD{})
+
+		_, err := interceptor(ctx, nil, info, handler)
+		require.NoError(t, err)
+
+		_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/api.OrderService/GetOrder"}, handler)
+		require.NoError(t, err)
+	})
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := transport.ParseTrustedProxies([]string{"10.0.0.0/8", " 127.0.0.1 ", "", "::1"})
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("127.0.0.1/32"),
+		netip.MustParsePrefix("::1/128"),
+	}, prefixes)
+
+	_, err = transport.ParseTrustedProxies([]string{"not-an-ip"})
+	require.Error(t, err)
+}
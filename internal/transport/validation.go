@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"buf.build/go/protovalidate"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ValidationInterceptor enforces the buf.validate rules declared in the
+// proto files before the request reaches the handler.
+func ValidationInterceptor(validator protovalidate.Validator) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if err := validate(ctx, validator, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamInterceptor validates every message received on a stream.
+func ValidationStreamInterceptor(validator protovalidate.Validator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: ss, validator: validator, method: info.FullMethod})
+	}
+}
+
+type validatingStream struct {
+	grpc.ServerStream
+	validator protovalidate.Validator
+	method    string
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return validate(s.Context(), s.validator, s.method, m)
+}
+
+func validate(ctx context.Context, validator protovalidate.Validator, method string, req any) error {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	err := validator.Validate(msg)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *protovalidate.ValidationError
+	if !errors.As(err, &validationErr) {
+		// broken rules are our bug, not the caller's
+		logger.GetLoggerFromCtx(ctx).Named("transport").Error(ctx, "request validation failed",
+			zap.String("method", method),
+			zap.Error(err),
+		)
+		return status.Error(codes.Internal, "request validation failed")
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	reasons := make([]string, 0, len(validationErr.Violations))
+	for _, v := range validationErr.Violations {
+		field := protovalidate.FieldPathString(v.Proto.GetField())
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: v.Proto.GetMessage(),
+			Reason:      v.Proto.GetRuleId(),
+		})
+		reasons = append(reasons, field+": "+v.Proto.GetMessage())
+	}
+
+	message := "invalid request: " + strings.Join(reasons, "; ")
+	st, detailsErr := status.New(codes.InvalidArgument, message).WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, message)
+	}
+
+	return st.Err()
+}
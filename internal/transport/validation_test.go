@@ -0,0 +1,97 @@
+package transport_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"buf.build/go/protovalidate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/internal/transport"
+	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger/loggertest"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func fieldViolations(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	st := status.Convert(err)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+
+	violations := map[string]string{}
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				violations[v.GetField()] = v.GetReason()
+			}
+		}
+	}
+
+	return violations
+}
+
+func TestValidationInterceptor(t *testing.T) {
+	ctx, _ := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+
+	validator, err := protovalidate.New()
+	require.NoError(t, err)
+	interceptor := transport.ValidationInterceptor(validator)
+
+	const validID = "8d3c2f0e-4b1a-4c5e-9f6d-2a7b8c9d0e1f"
+	tests := []struct {
+		name       string
+		req        proto.Message
+		violations map[string]string
+	}{
+		{name: "valid get", req: &api.GetOrderRequest{Id: validID}},
+		{name: "valid create", req: &api.CreateOrderRequest{Item: "laptop", Quantity: 1}},
+		{name: "valid update", req: &api.UpdateOrderRequest{Id: validID, Item: strings.Repeat("x", 255), Quantity: 10000}},
+		{
+			name:       "get with malformed id",
+			req:        &api.GetOrderRequest{Id: "not-a-uuid"},
+			violations: map[string]string{"id": "string.uuid"},
+		},
+		{
+			name:       "delete with empty id",
+			req:        &api.DeleteOrderRequest{},
+			violations: map[string]string{"id": "string.uuid_empty"},
+		},
+		{
+			name:       "create with empty item and zero quantity",
+			req:        &api.CreateOrderRequest{},
+			violations: map[string]string{"item": "string.min_len", "quantity": "int32.gt_lte"},
+		},
+		{
+			name:       "update with oversized item and quantity",
+			req:        &api.UpdateOrderRequest{Id: validID, Item: strings.Repeat("x", 256), Quantity: 10001},
+			violations: map[string]string{"item": "string.max_len", "quantity": "int32.gt_lte"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			_, err := interceptor(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: "/api.OrderService/Test"},
+				func(context.Context, any) (any, error) {
+					called = true
+					return nil, nil
+				})
+
+			if tt.violations == nil {
+				require.NoError(t, err)
+				assert.True(t, called)
+				return
+			}
+
+			assert.False(t, called, "invalid request must not reach the handler")
+			assert.Equal(t, tt.violations, fieldViolations(t, err))
+		})
+	}
+}
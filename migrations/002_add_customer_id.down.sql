@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_customer_id;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS customer_id;
\ No newline at end of file
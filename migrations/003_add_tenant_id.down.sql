@@ -0,0 +1,7 @@
+DROP POLICY IF EXISTS tenant_isolation ON orders;
+
+ALTER TABLE orders DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS idx_tenant_customer;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS tenant_id;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP FUNCTION IF EXISTS order_tenants();
+
+ALTER TABLE orders NO FORCE ROW LEVEL SECURITY;
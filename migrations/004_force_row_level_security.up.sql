@@ -0,0 +1,15 @@
+ALTER TABLE orders FORCE ROW LEVEL SECURITY;
+
+-- order_tenants lists tenants for cache warm-up. FORCE applies the policy to
+-- its owner too, so the role running migrations must be a superuser or have
+-- BYPASSRLS for the function to see every tenant; the service itself should
+-- connect as a role without those attributes.
+CREATE OR REPLACE FUNCTION order_tenants()
+    RETURNS SETOF VARCHAR(64)
+    LANGUAGE sql
+    STABLE
+    SECURITY DEFINER
+    SET search_path = public
+AS $$
+    SELECT DISTINCT tenant_id FROM orders
+$$;
@@ -7,6 +7,8 @@
 package test
 
 import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
@@ -27,6 +29,7 @@ type Order struct {
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Item          string                 `protobuf:"bytes,2,opt,name=item,proto3" json:"item,omitempty"`
 	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	CustomerId    string                 `protobuf:"bytes,4,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -82,6 +85,13 @@ func (x *Order) GetQuantity() int32 {
 	return 0
 }
 
+func (x *Order) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
 type CreateOrderRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Item          string                 `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
@@ -538,47 +548,237 @@ func (x *ListOrdersResponse) GetOrders() []*Order {
 	return nil
 }
 
+// Problem documents the application/problem+json body the gateway returns for
+// failed REST calls; gRPC clients receive google.rpc.Status instead.
+type Problem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Status        int32                  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`
+	Detail        string                 `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"`
+	Instance      string                 `protobuf:"bytes,5,opt,name=instance,proto3" json:"instance,omitempty"`
+	Code          string                 `protobuf:"bytes,6,opt,name=code,proto3" json:"code,omitempty"`
+	RequestId     string                 `protobuf:"bytes,7,opt,name=request_id,proto3" json:"request_id,omitempty"`
+	InvalidParams []*InvalidParam        `protobuf:"bytes,8,rep,name=invalid_params,proto3" json:"invalid_params,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Problem) Reset() {
+	*x = Problem{}
+	mi := &file_api_order_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Problem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Problem) ProtoMessage() {}
+
+func (x *Problem) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Problem.ProtoReflect.Descriptor instead.
+func (*Problem) Descriptor() ([]byte, []int) {
+	return file_api_order_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Problem) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Problem) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Problem) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *Problem) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *Problem) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *Problem) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Problem) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Problem) GetInvalidParams() []*InvalidParam {
+	if x != nil {
+		return x.InvalidParams
+	}
+	return nil
+}
+
+type InvalidParam struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvalidParam) Reset() {
+	*x = InvalidParam{}
+	mi := &file_api_order_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvalidParam) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvalidParam) ProtoMessage() {}
+
+func (x *InvalidParam) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvalidParam.ProtoReflect.Descriptor instead.
+func (*InvalidParam) Descriptor() ([]byte, []int) {
+	return file_api_order_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *InvalidParam) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *InvalidParam) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
 var File_api_order_proto protoreflect.FileDescriptor
 
 const file_api_order_proto_rawDesc = "" +
 	"\n" +
-	"\x0fapi/order.proto\x12\x03api\x1a\x1cgoogle/api/annotations.proto\"G\n" +
+	"\x0fapi/order.proto\x12\x03api\x1a\x1bbuf/validate/validate.proto\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"h\n" +
 	"\x05Order\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04item\x18\x02 \x01(\tR\x04item\x12\x1a\n" +
-	"\bquantity\x18\x03 \x01(\x05R\bquantity\"D\n" +
-	"\x12CreateOrderRequest\x12\x12\n" +
-	"\x04item\x18\x01 \x01(\tR\x04item\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x05R\bquantity\"%\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\x12\x1f\n" +
+	"\vcustomer_id\x18\x04 \x01(\tR\n" +
+	"customerId\"\\\n" +
+	"\x12CreateOrderRequest\x12\x1e\n" +
+	"\x04item\x18\x01 \x01(\tB\n" +
+	"\xbaH\ar\x05\x10\x01\x18\xff\x01R\x04item\x12&\n" +
+	"\bquantity\x18\x02 \x01(\x05B\n" +
+	"\xbaH\a\x1a\x05\x18\x90N \x00R\bquantity\"%\n" +
 	"\x13CreateOrderResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"!\n" +
-	"\x0fGetOrderRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"4\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"+\n" +
+	"\x0fGetOrderRequest\x12\x18\n" +
+	"\x02id\x18\x01 \x01(\tB\b\xbaH\x05r\x03\xb0\x01\x01R\x02id\"4\n" +
 	"\x10GetOrderResponse\x12 \n" +
 	"\x05order\x18\x01 \x01(\v2\n" +
-	".api.OrderR\x05order\"T\n" +
-	"\x12UpdateOrderRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04item\x18\x02 \x01(\tR\x04item\x12\x1a\n" +
-	"\bquantity\x18\x03 \x01(\x05R\bquantity\"7\n" +
+	".api.OrderR\x05order\"v\n" +
+	"\x12UpdateOrderRequest\x12\x18\n" +
+	"\x02id\x18\x01 \x01(\tB\b\xbaH\x05r\x03\xb0\x01\x01R\x02id\x12\x1e\n" +
+	"\x04item\x18\x02 \x01(\tB\n" +
+	"\xbaH\ar\x05\x10\x01\x18\xff\x01R\x04item\x12&\n" +
+	"\bquantity\x18\x03 \x01(\x05B\n" +
+	"\xbaH\a\x1a\x05\x18\x90N \x00R\bquantity\"7\n" +
 	"\x13UpdateOrderResponse\x12 \n" +
 	"\x05order\x18\x01 \x01(\v2\n" +
-	".api.OrderR\x05order\"$\n" +
-	"\x12DeleteOrderRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"/\n" +
+	".api.OrderR\x05order\".\n" +
+	"\x12DeleteOrderRequest\x12\x18\n" +
+	"\x02id\x18\x01 \x01(\tB\b\xbaH\x05r\x03\xb0\x01\x01R\x02id\"/\n" +
 	"\x13DeleteOrderResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x13\n" +
 	"\x11ListOrdersRequest\"8\n" +
 	"\x12ListOrdersResponse\x12\"\n" +
 	"\x06orders\x18\x01 \x03(\v2\n" +
-	".api.OrderR\x06orders2\xd9\x03\n" +
+	".api.OrderR\x06orders\"\xee\x01\n" +
+	"\aProblem\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x05R\x06status\x12\x16\n" +
+	"\x06detail\x18\x04 \x01(\tR\x06detail\x12\x1a\n" +
+	"\binstance\x18\x05 \x01(\tR\binstance\x12\x12\n" +
+	"\x04code\x18\x06 \x01(\tR\x04code\x12\x1e\n" +
+	"\n" +
+	"request_id\x18\a \x01(\tR\n" +
+	"request_id\x129\n" +
+	"\x0einvalid_params\x18\b \x03(\v2\x11.api.InvalidParamR\x0einvalid_params\":\n" +
+	"\fInvalidParam\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason2\xd9\x03\n" +
 	"\fOrderService\x12[\n" +
 	"\vCreateOrder\x12\x17.api.CreateOrderRequest\x1a\x18.api.CreateOrderResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/api/v1/orders\x12T\n" +
 	"\bGetOrder\x12\x14.api.GetOrderRequest\x1a\x15.api.GetOrderResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/api/v1/orders/{id}\x12`\n" +
 	"\vUpdateOrder\x12\x17.api.UpdateOrderRequest\x1a\x18.api.UpdateOrderResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\x1a\x13/api/v1/orders/{id}\x12]\n" +
 	"\vDeleteOrder\x12\x17.api.DeleteOrderRequest\x1a\x18.api.DeleteOrderResponse\"\x1b\x82\xd3\xe4\x93\x02\x15*\x13/api/v1/orders/{id}\x12U\n" +
 	"\n" +
-	"ListOrders\x12\x16.api.ListOrdersRequest\x1a\x17.api.ListOrdersResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/api/v1/ordersB\x0eZ\fpkg/api/testb\x06proto3"
+	"ListOrders\x12\x16.api.ListOrdersRequest\x1a\x17.api.ListOrdersResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/api/v1/ordersB\xcd\x02\x92A\xbb\x02\x12\x18\n" +
+	"\x11Order Service API2\x031.0*\x02\x01\x02:\x10application/json:\x18application/problem+jsonRc\n" +
+	"\adefault\x12X\n" +
+	"DError in RFC 7807 problem details format (application/problem+json).\x12\x10\n" +
+	"\x0e\x1a\f.api.ProblemZf\n" +
+	"\x1d\n" +
+	"\n" +
+	"ApiKeyAuth\x12\x0f\b\x02\x1a\tX-Api-Key \x02\n" +
+	"E\n" +
+	"\n" +
+	"BearerAuth\x127\b\x02\x12\"JWT access token: `Bearer <token>`\x1a\rAuthorization \x02b\x10\n" +
+	"\x0e\n" +
+	"\n" +
+	"BearerAuth\x12\x00b\x10\n" +
+	"\x0e\n" +
+	"\n" +
+	"ApiKeyAuth\x12\x00Z\fpkg/api/testb\x06proto3"
 
 var (
 	file_api_order_proto_rawDescOnce sync.Once
@@ -592,7 +792,7 @@ func file_api_order_proto_rawDescGZIP() []byte {
 	return file_api_order_proto_rawDescData
 }
 
-var file_api_order_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_api_order_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_api_order_proto_goTypes = []any{
 	(*Order)(nil),               // 0: api.Order
 	(*CreateOrderRequest)(nil),  // 1: api.CreateOrderRequest
@@ -605,26 +805,29 @@ var file_api_order_proto_goTypes = []any{
 	(*DeleteOrderResponse)(nil), // 8: api.DeleteOrderResponse
 	(*ListOrdersRequest)(nil),   // 9: api.ListOrdersRequest
 	(*ListOrdersResponse)(nil),  // 10: api.ListOrdersResponse
+	(*Problem)(nil),             // 11: api.Problem
+	(*InvalidParam)(nil),        // 12: api.InvalidParam
 }
 var file_api_order_proto_depIdxs = []int32{
 	0,  // 0: api.GetOrderResponse.order:type_name -> api.Order
 	0,  // 1: api.UpdateOrderResponse.order:type_name -> api.Order
 	0,  // 2: api.ListOrdersResponse.orders:type_name -> api.Order
-	1,  // 3: api.OrderService.CreateOrder:input_type -> api.CreateOrderRequest
-	3,  // 4: api.OrderService.GetOrder:input_type -> api.GetOrderRequest
-	5,  // 5: api.OrderService.UpdateOrder:input_type -> api.UpdateOrderRequest
-	7,  // 6: api.OrderService.DeleteOrder:input_type -> api.DeleteOrderRequest
-	9,  // 7: api.OrderService.ListOrders:input_type -> api.ListOrdersRequest
-	2,  // 8: api.OrderService.CreateOrder:output_type -> api.CreateOrderResponse
-	4,  // 9: api.OrderService.GetOrder:output_type -> api.GetOrderResponse
-	6,  // 10: api.OrderService.UpdateOrder:output_type -> api.UpdateOrderResponse
-	8,  // 11: api.OrderService.DeleteOrder:output_type -> api.DeleteOrderResponse
-	10, // 12: api.OrderService.ListOrders:output_type -> api.ListOrdersResponse
-	8,  // [8:13] is the sub-list for method output_type
-	3,  // [3:8] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	12, // 3: api.Problem.invalid_params:type_name -> api.InvalidParam
+	1,  // 4: api.OrderService.CreateOrder:input_type -> api.CreateOrderRequest
+	3,  // 5: api.OrderService.GetOrder:input_type -> api.GetOrderRequest
+	5,  // 6: api.OrderService.UpdateOrder:input_type -> api.UpdateOrderRequest
+	7,  // 7: api.OrderService.DeleteOrder:input_type -> api.DeleteOrderRequest
+	9,  // 8: api.OrderService.ListOrders:input_type -> api.ListOrdersRequest
+	2,  // 9: api.OrderService.CreateOrder:output_type -> api.CreateOrderResponse
+	4,  // 10: api.OrderService.GetOrder:output_type -> api.GetOrderResponse
+	6,  // 11: api.OrderService.UpdateOrder:output_type -> api.UpdateOrderResponse
+	8,  // 12: api.OrderService.DeleteOrder:output_type -> api.DeleteOrderResponse
+	10, // 13: api.OrderService.ListOrders:output_type -> api.ListOrdersResponse
+	9,  // [9:14] is the sub-list for method output_type
+	4,  // [4:9] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_api_order_proto_init() }
@@ -638,7 +841,7 @@ func file_api_order_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_order_proto_rawDesc), len(file_api_order_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
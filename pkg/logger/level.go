@@ -0,0 +1,233 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type LevelCfg struct {
+	EndpointEnabled bool `env:"LOG_LEVEL_ENDPOINT_ENABLED" env-default:"false"`
+}
+
+// Levels holds the global level and per-logger overrides. Logger names are
+// the dotted names produced by Named, an override for "repository" also
+// applies to "repository.cache" unless that has its own. base is the
+// configured global level that ResetLevel("") returns to.
+type Levels struct {
+	mu        sync.RWMutex
+	base      zapcore.Level
+	global    zap.AtomicLevel
+	overrides map[string]zapcore.Level
+	reverts   map[string]*time.Timer
+}
+
+func NewLevels(level zapcore.Level) *Levels {
+	return &Levels{
+		base:      level,
+		global:    zap.NewAtomicLevelAt(level),
+		overrides: make(map[string]zapcore.Level),
+		reverts:   make(map[string]*time.Timer),
+	}
+}
+
+func (lv *Levels) Level(name string) zapcore.Level {
+	lv.mu.RLock()
+	defer lv.mu.RUnlock()
+
+	for name != "" {
+		if level, ok := lv.overrides[name]; ok {
+			return level
+		}
+
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+
+	return lv.global.Level()
+}
+
+// SetLevel changes the level of the named logger, or the global level when
+// name is empty. A positive revertAfter restores the previous state once it
+// elapses, unless the level was changed again in the meantime.
+func (lv *Levels) SetLevel(name string, level zapcore.Level, revertAfter time.Duration) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	prev, hadPrev := lv.overrides[name]
+	if name == "" {
+		prev, hadPrev = lv.global.Level(), true
+		lv.global.SetLevel(level)
+	} else {
+		lv.overrides[name] = level
+	}
+
+	lv.scheduleRevert(name, revertAfter, func() {
+		switch {
+		case name == "":
+			lv.global.SetLevel(prev)
+		case hadPrev:
+			lv.overrides[name] = prev
+		default:
+			delete(lv.overrides, name)
+		}
+	})
+}
+
+// ResetLevel drops the override of the named logger, or restores the
+// configured global level when name is empty, and cancels a pending revert.
+func (lv *Levels) ResetLevel(name string) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	if name == "" {
+		lv.global.SetLevel(lv.base)
+	} else {
+		delete(lv.overrides, name)
+	}
+	lv.scheduleRevert(name, 0, nil)
+}
+
+func (lv *Levels) scheduleRevert(name string, after time.Duration, revert func()) {
+	if t, ok := lv.reverts[name]; ok {
+		t.Stop()
+		delete(lv.reverts, name)
+	}
+
+	if after <= 0 {
+		return
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(after, func() {
+		lv.mu.Lock()
+		defer lv.mu.Unlock()
+
+		if lv.reverts[name] != t {
+			return
+		}
+		delete(lv.reverts, name)
+		revert()
+	})
+	lv.reverts[name] = t
+}
+
+func (lv *Levels) minLevel() zapcore.Level {
+	lv.mu.RLock()
+	defer lv.mu.RUnlock()
+
+	minLevel := lv.global.Level()
+	for _, level := range lv.overrides {
+		minLevel = min(minLevel, level)
+	}
+
+	return minLevel
+}
+
+type levelState struct {
+	Level   string            `json:"level"`
+	Loggers map[string]string `json:"loggers"`
+}
+
+func (lv *Levels) state() levelState {
+	lv.mu.RLock()
+	defer lv.mu.RUnlock()
+
+	s := levelState{Level: lv.global.Level().String(), Loggers: make(map[string]string, len(lv.overrides))}
+	for name, level := range lv.overrides {
+		s.Loggers[name] = level.String()
+	}
+
+	return s
+}
+
+type levelRequest struct {
+	Level    string `json:"level"`
+	Logger   string `json:"logger"`
+	Duration string `json:"duration"`
+}
+
+// Handler serves the current levels on GET, changes a level on PUT with
+// {"level": "debug", "logger": "cache", "duration": "5m"} and drops a
+// per-logger override on DELETE ?logger=name, or restores the configured
+// global level on DELETE without a logger.
+func (lv *Levels) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			if err := lv.apply(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		case http.MethodDelete:
+			lv.ResetLevel(r.URL.Query().Get("logger"))
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(lv.state())
+	})
+}
+
+func (lv *Levels) apply(r *http.Request) error {
+	var req levelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<10)).Decode(&req); err != nil {
+		return fmt.Errorf("decode request: %w", err)
+	}
+
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		return fmt.Errorf("parse level: %w", err)
+	}
+
+	var revertAfter time.Duration
+	if req.Duration != "" {
+		revertAfter, err = time.ParseDuration(req.Duration)
+		if err != nil {
+			return fmt.Errorf("parse duration: %w", err)
+		}
+	}
+
+	lv.SetLevel(req.Logger, level, revertAfter)
+
+	return nil
+}
+
+// Wrap filters entries of core by the runtime levels.
+func (lv *Levels) Wrap(core zapcore.Core) zapcore.Core {
+	return &levelCore{Core: core, levels: lv}
+}
+
+type levelCore struct {
+	zapcore.Core
+	levels *Levels
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return level >= c.levels.minLevel()
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.levels.Level(ent.LoggerName) {
+		return ce
+	}
+
+	return c.Core.Check(ent, ce)
+}
@@ -2,47 +2,96 @@ package logger
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
+	"unicode"
 
+	"github.com/google/uuid"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/grpcutil"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 type loggerKey string
 
 const (
-	key loggerKey = "logger"
+	key          loggerKey = "logger"
+	requestIDKey loggerKey = "request_id"
+
+	RequestIDHeader = "x-request-id"
+	maxRequestIDLen = 128
 )
 
 type Logger struct {
-	z *zap.Logger
+	z       *zap.Logger
+	payload *PayloadPolicy
+	levels  *Levels
+	method  string
 }
 
 func New(ctx context.Context, env string) (context.Context, error) {
-	loggerCfg := zap.NewProductionConfig()
+	return NewWithOutput(ctx, env, OutputCfg{
+		Format:             FormatJSON,
+		SamplingEnabled:    true,
+		SamplingInitial:    100,
+		SamplingThereafter: 100,
+	})
+}
 
-	loggerCfg.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
-	if env == "dev" {
-		loggerCfg.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
-	}
+func FromZap(z *zap.Logger) *Logger {
+	return &Logger{z: z, payload: NewPayloadPolicy(PayloadCfg{})}
+}
 
-	logger, err := loggerCfg.Build()
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err = logger.Sync(); err != nil {
-			logger.Error("failed to syncronize logger: %w", zap.Error(err))
-		}
-	}()
+func GetLoggerFromCtx(ctx context.Context) *Logger {
+	return ctx.Value(key).(*Logger)
+}
 
-	ctx = context.WithValue(ctx, key, &Logger{logger})
+func WithLogger(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, key, l)
+}
 
-	return ctx, nil
+func RequestIDFromCtx(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
 }
 
-func GetLoggerFromCtx(ctx context.Context) *Logger {
-	return ctx.Value(key).(*Logger)
+func (l *Logger) With(fields ...zap.Field) *Logger {
+	return &Logger{z: l.z.With(fields...), payload: l.payload, levels: l.levels, method: l.method}
+}
+
+func (l *Logger) Named(name string) *Logger {
+	return &Logger{z: l.z.Named(name), payload: l.payload, levels: l.levels, method: l.method}
+}
+
+func (l *Logger) WithPayloadPolicy(p *PayloadPolicy) *Logger {
+	return &Logger{z: l.z, payload: p, levels: l.levels, method: l.method}
+}
+
+// WithLevels attaches the runtime level control that wraps the logger's core,
+// so Levels can hand it to the /admin/log-level endpoint.
+func (l *Logger) WithLevels(levels *Levels) *Logger {
+	return &Logger{z: l.z, payload: l.payload, levels: levels, method: l.method}
+}
+
+// Levels returns the runtime level control, nil for loggers not created by New.
+func (l *Logger) Levels() *Levels {
+	return l.levels
+}
+
+func (l *Logger) forMethod(method string) *Logger {
+	return &Logger{z: l.z, payload: l.payload, levels: l.levels, method: method}
+}
+
+// Payload renders a request or response according to the payload policy of
+// the method this logger was scoped to.
+func (l *Logger) Payload(key string, payload any) zap.Field {
+	return l.payload.Field(l.method, key, payload)
+}
+
+func (l *Logger) Sync() error {
+	return l.z.Sync()
 }
 
 func (l *Logger) Info(ctx context.Context, msg string, fields ...zap.Field) {
@@ -64,6 +113,32 @@ func (l *Logger) Debug(ctx context.Context, msg string, fields ...zap.Field) {
 	l.z.Debug(msg, fields...)
 }
 
+// RequestIDInterceptor takes x-request-id from the incoming metadata (or
+// generates one), stores it in ctx and echoes it in headers and trailers.
+func RequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		ctx, _ = unaryRequestID(ctx)
+
+		return handler(ctx, req)
+	}
+}
+
+func RequestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, _ := withRequestID(ss.Context(), ss.SetHeader, ss.SetTrailer)
+
+		return handler(srv, grpcutil.WithContext(ss, ctx))
+	}
+}
+
+// LoggerInterceptor puts a request-scoped logger into ctx and logs the
+// request and its outcome. It assigns a request ID itself when
+// RequestIDInterceptor did not run before it.
 func LoggerInterceptor(rootCtx context.Context) grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context,
@@ -71,13 +146,13 @@ func LoggerInterceptor(rootCtx context.Context) grpc.UnaryServerInterceptor {
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (any, error) {
-		logger := GetLoggerFromCtx(rootCtx)
-		ctx = context.WithValue(ctx, key, GetLoggerFromCtx(rootCtx))
+		ctx, requestID := unaryRequestID(ctx)
+		logger := requestLogger(rootCtx, ctx, info.FullMethod, requestID)
+		ctx = WithLogger(ctx, logger)
 
 		logger.Info(ctx,
 			"incoming request",
-			zap.String("method", info.FullMethod),
-			zap.Any("request", req),
+			logger.Payload("request", req),
 		)
 
 		start := time.Now()
@@ -87,15 +162,13 @@ func LoggerInterceptor(rootCtx context.Context) grpc.UnaryServerInterceptor {
 		if err != nil {
 			logger.Error(ctx,
 				"request failed",
-				zap.String("method", info.FullMethod),
 				zap.Error(err),
 				zap.Duration("duration", duration),
 			)
 		} else {
 			logger.Info(ctx,
 				"request completed",
-				zap.String("method", info.FullMethod),
-				zap.Any("response", resp),
+				logger.Payload("response", resp),
 				zap.Duration("duration", duration),
 			)
 		}
@@ -103,3 +176,128 @@ func LoggerInterceptor(rootCtx context.Context) grpc.UnaryServerInterceptor {
 		return resp, err
 	}
 }
+
+// LoggerStreamInterceptor logs stream start and end; individual messages are
+// logged at debug level with the same payload policy as unary calls.
+func LoggerStreamInterceptor(rootCtx context.Context) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, requestID := withRequestID(ss.Context(), ss.SetHeader, ss.SetTrailer)
+		logger := requestLogger(rootCtx, ctx, info.FullMethod, requestID)
+		ctx = WithLogger(ctx, logger)
+
+		logger.Info(ctx, "stream started",
+			zap.Bool("client_stream", info.IsClientStream),
+			zap.Bool("server_stream", info.IsServerStream),
+		)
+
+		stream := &loggingStream{ServerStream: grpcutil.WithContext(ss, ctx), ctx: ctx, logger: logger}
+		start := time.Now()
+		err := handler(srv, stream)
+
+		fields := []zap.Field{
+			zap.Duration("duration", time.Since(start)),
+			zap.Int64("messages_received", stream.received.Load()),
+			zap.Int64("messages_sent", stream.sent.Load()),
+		}
+		if err != nil {
+			logger.Error(ctx, "stream failed", append(fields, zap.Error(err))...)
+		} else {
+			logger.Info(ctx, "stream completed", fields...)
+		}
+
+		return err
+	}
+}
+
+type loggingStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	logger   *Logger
+	received atomic.Int64
+	sent     atomic.Int64
+}
+
+func (s *loggingStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Add(1)
+		s.logger.Debug(s.ctx, "stream message received", s.logger.Payload("request", m))
+	}
+
+	return err
+}
+
+func (s *loggingStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+		s.logger.Debug(s.ctx, "stream message sent", s.logger.Payload("response", m))
+	}
+
+	return err
+}
+
+func requestLogger(rootCtx, ctx context.Context, method, requestID string) *Logger {
+	fields := []zap.Field{
+		zap.String("request_id", requestID),
+		zap.String("method", method),
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", sc.TraceID().String()),
+			zap.String("span_id", sc.SpanID().String()),
+		)
+	}
+
+	return GetLoggerFromCtx(rootCtx).forMethod(method).With(fields...)
+}
+
+func unaryRequestID(ctx context.Context) (context.Context, string) {
+	return withRequestID(ctx,
+		func(md metadata.MD) error { return grpc.SetHeader(ctx, md) },
+		func(md metadata.MD) { _ = grpc.SetTrailer(ctx, md) },
+	)
+}
+
+// withRequestID is a no-op when an earlier interceptor already assigned the
+// request ID, so the ID interceptor and the loggers can run in any order.
+func withRequestID(
+	ctx context.Context,
+	setHeader func(metadata.MD) error,
+	setTrailer func(metadata.MD),
+) (context.Context, string) {
+	if id := RequestIDFromCtx(ctx); id != "" {
+		return ctx, id
+	}
+
+	requestID := incomingRequestID(ctx)
+	md := metadata.Pairs(RequestIDHeader, requestID)
+	if err := setHeader(md); err == nil {
+		setTrailer(md)
+	}
+
+	return context.WithValue(ctx, requestIDKey, requestID), requestID
+}
+
+func incomingRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(RequestIDHeader); len(values) > 0 && validRequestID(values[0]) {
+		return values[0]
+	}
+
+	return uuid.NewString()
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+
+	for _, r := range id {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,382 @@
+package logger_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/api/test"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger/loggertest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestLoggerInterceptor_RequestScopedLogger(t *testing.T) {
+	rootCtx, logs := loggertest.NewObserved(context.Background(), zap.DebugLevel)
+
+	interceptor := logger.LoggerInterceptor(rootCtx)
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.OrderService/GetOrder"}
+
+	t.Run("uses incoming request id", func(t *testing.T) {
+		logs.TakeAll()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-123"))
+
+		_, err := interceptor(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+			assert.Equal(t, "req-123", logger.RequestIDFromCtx(ctx))
+			logger.GetLoggerFromCtx(ctx).Info(ctx, "handler log")
+			return "ok", nil
+		})
+		require.NoError(t, err)
+
+		handlerLogs := logs.FilterMessage("handler log").All()
+		require.Len(t, handlerLogs, 1)
+
+		fields := handlerLogs[0].ContextMap()
+		assert.Equal(t, "req-123", fields["request_id"])
+		assert.Equal(t, "/api.OrderService/GetOrder", fields["method"])
+	})
+
+	t.Run("generates request id when absent", func(t *testing.T) {
+		logs.TakeAll()
+
+		var requestID string
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, _ any) (any, error) {
+			requestID = logger.RequestIDFromCtx(ctx)
+			return "ok", nil
+		})
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, requestID)
+		for _, entry := range logs.All() {
+			assert.Equal(t, requestID, entry.ContextMap()["request_id"])
+		}
+	})
+
+	t.Run("rejects malformed request id", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "bad\nid"))
+
+		_, err := interceptor(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+			assert.NotEqual(t, "bad\nid", logger.RequestIDFromCtx(ctx))
+			return "ok", nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("root logger is not modified", func(t *testing.T) {
+		logs.TakeAll()
+		logger.GetLoggerFromCtx(rootCtx).Info(rootCtx, "root log")
+
+		entries := logs.FilterMessage("root log").All()
+		require.Len(t, entries, 1)
+		assert.NotContains(t, entries[0].ContextMap(), "request_id")
+	})
+}
+
+type stubServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+	recv   int
+}
+
+func (s *stubServerStream) Context() context.Context { return s.ctx }
+
+func (s *stubServerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *stubServerStream) SetTrailer(metadata.MD) {}
+
+func (s *stubServerStream) SendMsg(any) error { return nil }
+
+func (s *stubServerStream) RecvMsg(any) error {
+	if s.recv == 0 {
+		return io.EOF
+	}
+	s.recv--
+
+	return nil
+}
+
+func TestLoggerStreamInterceptor(t *testing.T) {
+	rootCtx, logs := loggertest.NewObserved(context.Background(), zap.DebugLevel)
+	info := &grpc.StreamServerInfo{FullMethod: "/api.OrderService/WatchOrders", IsClientStream: true}
+
+	ss := &stubServerStream{
+		ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-1")),
+		recv: 2,
+	}
+	chain := func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return logger.RequestIDStreamInterceptor()(srv, ss, info, func(srv any, ss grpc.ServerStream) error {
+			return logger.LoggerStreamInterceptor(rootCtx)(srv, ss, info, handler)
+		})
+	}
+
+	err := chain(nil, ss, info, func(_ any, stream grpc.ServerStream) error {
+		ctx := stream.Context()
+		assert.Equal(t, "req-1", logger.RequestIDFromCtx(ctx))
+		logger.GetLoggerFromCtx(ctx).Info(ctx, "handler log")
+
+		for stream.RecvMsg(&api.GetOrderRequest{}) == nil {
+			require.NoError(t, stream.SendMsg(&api.Order{Id: "1"}))
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"req-1"}, ss.header.Get("x-request-id"), "request id echoed once")
+	assert.Equal(t, []string{
+		"stream started",
+		"handler log",
+		"stream message received",
+		"stream message sent",
+		"stream message received",
+		"stream message sent",
+		"stream completed",
+	}, messages(logs.All()))
+
+	for _, entry := range logs.All() {
+		assert.Equal(t, "req-1", entry.ContextMap()["request_id"])
+	}
+	completed := logs.FilterMessage("stream completed").All()[0].ContextMap()
+	assert.EqualValues(t, 2, completed["messages_received"])
+	assert.EqualValues(t, 2, completed["messages_sent"])
+}
+
+func TestPayloadPolicy(t *testing.T) {
+	resp := &api.ListOrdersResponse{Orders: []*api.Order{
+		{Id: "1", Item: "book", Quantity: 2, CustomerId: "alice@example.com"},
+	}}
+
+	tests := []struct {
+		name     string
+		cfg      logger.PayloadCfg
+		method   string
+		contains []string
+		absent   []string
+		skipped  bool
+	}{
+		{
+			name:     "redacts field by name at any depth",
+			cfg:      logger.PayloadCfg{RedactFields: []string{"customer_id"}},
+			contains: []string{"[REDACTED]", "book"},
+			absent:   []string{"alice@example.com"},
+		},
+		{
+			name:     "redacts field by full path",
+			cfg:      logger.PayloadCfg{RedactFields: []string{"orders.item"}},
+			contains: []string{"alice@example.com", "[REDACTED]"},
+			absent:   []string{"book"},
+		},
+		{
+			name:     "truncates large payloads",
+			cfg:      logger.PayloadCfg{MaxPayloadBytes: 10},
+			contains: []string{"truncated"},
+			absent:   []string{"alice@example.com"},
+		},
+		{
+			name:     "metadata policy for method",
+			cfg:      logger.PayloadCfg{MethodPolicies: map[string]string{"/api.OrderService/ListOrders": logger.PayloadMetadata}},
+			method:   "/api.OrderService/ListOrders",
+			contains: []string{"ListOrdersResponse", "bytes"},
+			absent:   []string{"book"},
+		},
+		{
+			name:    "none policy skips payload",
+			cfg:     logger.PayloadCfg{Policy: logger.PayloadNone},
+			skipped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := logger.NewPayloadPolicy(tt.cfg).Field(tt.method, "response", resp)
+			if tt.skipped {
+				assert.Equal(t, zap.Skip(), field)
+				return
+			}
+
+			for _, s := range tt.contains {
+				assert.Contains(t, field.String, s)
+			}
+			for _, s := range tt.absent {
+				assert.NotContains(t, field.String, s)
+			}
+		})
+	}
+
+	assert.Equal(t, "alice@example.com", resp.GetOrders()[0].GetCustomerId(), "original message must not be modified")
+}
+
+func TestPayloadPolicy_TruncatesOnRuneBoundary(t *testing.T) {
+	resp := &api.ListOrdersResponse{Orders: []*api.Order{{Id: "1", Item: "книга для записей"}}}
+
+	for limit := 20; limit < 40; limit++ {
+		field := logger.NewPayloadPolicy(logger.PayloadCfg{MaxPayloadBytes: limit}).Field("", "response", resp)
+		assert.Contains(t, field.String, "truncated")
+		assert.True(t, utf8.ValidString(field.String), "limit %d: %q", limit, field.String)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	levels := logger.NewLevels(zap.InfoLevel)
+	z := zap.New(levels.Wrap(core))
+
+	z.Named("cache").Debug("hidden")
+	levels.SetLevel("cache", zap.DebugLevel, 0)
+	z.Named("cache").Debug("cache debug")
+	z.Named("cache").Named("redis").Debug("nested debug")
+	z.Named("repository").Debug("hidden")
+
+	assert.Equal(t, []string{"cache debug", "nested debug"}, messages(logs.TakeAll()))
+
+	levels.SetLevel("", zap.WarnLevel, 0)
+	z.Info("hidden")
+	z.Warn("global warn")
+	assert.Equal(t, []string{"global warn"}, messages(logs.TakeAll()))
+
+	levels.ResetLevel("cache")
+	assert.Equal(t, zap.WarnLevel, levels.Level("cache"))
+}
+
+func TestLevels_AutoRevert(t *testing.T) {
+	levels := logger.NewLevels(zap.InfoLevel)
+
+	levels.SetLevel("", zap.DebugLevel, 20*time.Millisecond)
+	levels.SetLevel("cache", zap.ErrorLevel, 20*time.Millisecond)
+	assert.Equal(t, zap.DebugLevel, levels.Level(""))
+	assert.Equal(t, zap.ErrorLevel, levels.Level("cache"))
+
+	assert.Eventually(t, func() bool {
+		return levels.Level("") == zap.InfoLevel && levels.Level("cache") == zap.InfoLevel
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestLevels_Handler(t *testing.T) {
+	levels := logger.NewLevels(zap.InfoLevel)
+	handler := levels.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/log-level",
+		strings.NewReader(`{"level":"debug","logger":"cache"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"info","loggers":{"cache":"debug"}}`, rec.Body.String())
+	assert.Equal(t, zap.DebugLevel, levels.Level("cache"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/log-level",
+		strings.NewReader(`{"level":"verbose"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/log-level?logger=cache", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"info","loggers":{}}`, rec.Body.String())
+
+	t.Run("delete without logger restores configured global level", func(t *testing.T) {
+		levels.SetLevel("", zap.WarnLevel, 0)
+		levels.SetLevel("", zap.DebugLevel, 20*time.Millisecond)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/log-level", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"level":"info","loggers":{}}`, rec.Body.String())
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, zap.InfoLevel, levels.Level(""), "pending revert is cancelled")
+	})
+}
+
+func messages(entries []observer.LoggedEntry) []string {
+	var msgs []string
+	for _, e := range entries {
+		msgs = append(msgs, e.Message)
+	}
+
+	return msgs
+}
+
+func TestNewWithOutput(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+
+	ctx, err := logger.NewWithOutput(context.Background(), "", logger.OutputCfg{
+		Format:  logger.FormatConsole,
+		Outputs: []string{first, second},
+	})
+	require.NoError(t, err)
+
+	log := logger.GetLoggerFromCtx(ctx)
+	log.Debug(ctx, "hidden")
+	log.Info(ctx, "written to both", zap.String("k", "v"))
+	require.NoError(t, log.Sync())
+
+	for _, path := range []string{first, second} {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "INFO")
+		assert.Contains(t, string(data), "written to both")
+		assert.NotContains(t, string(data), "hidden")
+		assert.False(t, strings.HasPrefix(string(data), "{"), "console format expected")
+	}
+
+	_, err = logger.NewWithOutput(context.Background(), "", logger.OutputCfg{Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestNewWithOutput_SamplingKeepsWarnings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sampled.log")
+
+	ctx, err := logger.NewWithOutput(context.Background(), "", logger.OutputCfg{
+		Outputs:            []string{path},
+		SamplingEnabled:    true,
+		SamplingInitial:    1,
+		SamplingThereafter: 1000,
+	})
+	require.NoError(t, err)
+
+	log := logger.GetLoggerFromCtx(ctx)
+	for range 10 {
+		log.Info(ctx, "noisy")
+		log.Warn(ctx, "important")
+	}
+	require.NoError(t, log.Sync())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "noisy"))
+	assert.Equal(t, 10, strings.Count(string(data), "important"))
+}
+
+func TestNewObserved(t *testing.T) {
+	ctx, logs := loggertest.NewObserved(context.Background(), zap.InfoLevel)
+
+	log := logger.GetLoggerFromCtx(ctx)
+	log.Debug(ctx, "hidden")
+	log.Named("cache").Info(ctx, "visible", zap.String("k", "v"))
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "cache", entries[0].LoggerName)
+	assert.Equal(t, "v", entries[0].ContextMap()["k"])
+
+	log.Levels().SetLevel("", zap.DebugLevel, 0)
+	log.Debug(ctx, "now visible")
+	assert.Equal(t, 1, logs.FilterMessage("now visible").Len())
+}
@@ -0,0 +1,23 @@
+// Package loggertest provides in-memory loggers for tests, keeping the
+// zaptest dependency out of the logger package itself.
+package loggertest
+
+import (
+	"context"
+
+	"gitlab.crja72.ru/golang/2025/spring/course/students/268295-aisavelev-edu.hse.ru-course-1478/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// NewObserved returns a context with a logger that keeps entries in memory,
+// so tests can assert on what was logged.
+func NewObserved(ctx context.Context, level zapcore.Level) (context.Context, *observer.ObservedLogs) {
+	levels := logger.NewLevels(level)
+	core, logs := observer.New(zap.DebugLevel)
+
+	l := logger.FromZap(zap.New(levels.Wrap(core))).WithLevels(levels)
+
+	return logger.WithLogger(ctx, l), logs
+}
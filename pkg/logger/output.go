@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+
+	outputStdout = "stdout"
+	outputStderr = "stderr"
+)
+
+// OutputCfg describes where and how logs are written. Outputs accepts
+// "stdout", "stderr" and file paths, files are rotated by size and age.
+// Sampling only applies to Debug and Info, warnings and errors are never dropped.
+type OutputCfg struct {
+	Format             string   `env:"LOG_FORMAT"              env-default:"json"`
+	Outputs            []string `env:"LOG_OUTPUTS"             env-default:"stderr" env-separator:","`
+	SamplingEnabled    bool     `env:"LOG_SAMPLING_ENABLED"    env-default:"true"`
+	SamplingInitial    int      `env:"LOG_SAMPLING_INITIAL"    env-default:"100"`
+	SamplingThereafter int      `env:"LOG_SAMPLING_THEREAFTER" env-default:"100"`
+	FileMaxSizeMB      int      `env:"LOG_FILE_MAX_SIZE_MB"    env-default:"100"`
+	FileMaxBackups     int      `env:"LOG_FILE_MAX_BACKUPS"    env-default:"5"`
+	FileMaxAgeDays     int      `env:"LOG_FILE_MAX_AGE_DAYS"   env-default:"30"`
+	FileCompress       bool     `env:"LOG_FILE_COMPRESS"       env-default:"true"`
+}
+
+func NewWithOutput(ctx context.Context, env string, cfg OutputCfg) (context.Context, error) {
+	levels := NewLevels(zap.InfoLevel)
+	if env == "dev" {
+		levels = NewLevels(zap.DebugLevel)
+	}
+
+	encoder, err := newEncoder(cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	sink, err := newSink(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	verbose := zapcore.NewCore(encoder, sink, zap.LevelEnablerFunc(func(l zapcore.Level) bool { return l < zap.WarnLevel }))
+	if cfg.SamplingEnabled {
+		verbose = zapcore.NewSamplerWithOptions(verbose, time.Second, cfg.SamplingInitial, cfg.SamplingThereafter)
+	}
+	important := zapcore.NewCore(encoder, sink, zap.LevelEnablerFunc(func(l zapcore.Level) bool { return l >= zap.WarnLevel }))
+
+	z := zap.New(levels.Wrap(zapcore.NewTee(verbose, important)),
+		zap.AddCaller(),
+		zap.AddStacktrace(zap.ErrorLevel),
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+	)
+
+	return WithLogger(ctx, FromZap(z).WithLevels(levels)), nil
+}
+
+func newEncoder(format string) (zapcore.Encoder, error) {
+	switch format {
+	case "", FormatJSON:
+		return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), nil
+	case FormatConsole:
+		encCfg := zap.NewProductionEncoderConfig()
+		encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		encCfg.EncodeLevel = zapcore.CapitalLevelEncoder
+		return zapcore.NewConsoleEncoder(encCfg), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+}
+
+func newSink(cfg OutputCfg) (zapcore.WriteSyncer, error) {
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{outputStderr}
+	}
+
+	syncers := make([]zapcore.WriteSyncer, 0, len(outputs))
+	for _, out := range outputs {
+		switch out {
+		case "":
+			return nil, fmt.Errorf("empty log output")
+		case outputStdout:
+			syncers = append(syncers, zapcore.Lock(os.Stdout))
+		case outputStderr:
+			syncers = append(syncers, zapcore.Lock(os.Stderr))
+		default:
+			syncers = append(syncers, zapcore.AddSync(&lumberjack.Logger{
+				Filename:   out,
+				MaxSize:    cfg.FileMaxSizeMB,
+				MaxBackups: cfg.FileMaxBackups,
+				MaxAge:     cfg.FileMaxAgeDays,
+				Compress:   cfg.FileCompress,
+			}))
+		}
+	}
+
+	return zapcore.NewMultiWriteSyncer(syncers...), nil
+}
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	PayloadFull     = "full"
+	PayloadMetadata = "metadata"
+	PayloadNone     = "none"
+
+	redactedValue = "[REDACTED]"
+)
+
+type PayloadCfg struct {
+	Policy          string            `env:"LOG_PAYLOAD_POLICY"          env-default:"full"`
+	MethodPolicies  map[string]string `env:"LOG_PAYLOAD_METHOD_POLICIES"`
+	RedactFields    []string          `env:"LOG_REDACT_FIELDS"           env-default:"customer_id" env-separator:","`
+	MaxPayloadBytes int               `env:"LOG_MAX_PAYLOAD_BYTES"       env-default:"2048"`
+}
+
+// PayloadPolicy decides how request and response payloads end up in logs.
+// Redact entries without a dot match a field name at any depth, dotted
+// entries match the full path from the root message (e.g. "orders.item").
+type PayloadPolicy struct {
+	policy          string
+	methodPolicies  map[string]string
+	redactNames     []string
+	redactPaths     []string
+	maxPayloadBytes int
+}
+
+func NewPayloadPolicy(cfg PayloadCfg) *PayloadPolicy {
+	p := &PayloadPolicy{
+		policy:          cfg.Policy,
+		methodPolicies:  cfg.MethodPolicies,
+		maxPayloadBytes: cfg.MaxPayloadBytes,
+	}
+
+	if p.policy == "" {
+		p.policy = PayloadFull
+	}
+
+	for _, field := range cfg.RedactFields {
+		field = strings.TrimSpace(field)
+		switch {
+		case field == "":
+		case strings.Contains(field, "."):
+			p.redactPaths = append(p.redactPaths, field)
+		default:
+			p.redactNames = append(p.redactNames, field)
+		}
+	}
+
+	return p
+}
+
+func (p *PayloadPolicy) Field(method, key string, payload any) zap.Field {
+	policy := p.policy
+	if override, ok := p.methodPolicies[method]; ok {
+		policy = override
+	}
+
+	switch policy {
+	case PayloadNone:
+		return zap.Skip()
+	case PayloadMetadata:
+		return zap.String(key, p.describe(payload))
+	default:
+		return zap.String(key, p.truncate(p.render(payload)))
+	}
+}
+
+func (p *PayloadPolicy) render(payload any) string {
+	msg, ok := payload.(proto.Message)
+	if !ok || msg == nil {
+		return fmt.Sprintf("%v", payload)
+	}
+
+	if len(p.redactNames) > 0 || len(p.redactPaths) > 0 {
+		msg = proto.Clone(msg)
+		p.redact(msg.ProtoReflect(), "")
+	}
+
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return fmt.Sprintf("<unrenderable %T: %v>", payload, err)
+	}
+
+	return string(data)
+}
+
+func (p *PayloadPolicy) describe(payload any) string {
+	if msg, ok := payload.(proto.Message); ok && msg != nil {
+		return fmt.Sprintf("<%s, %d bytes>", msg.ProtoReflect().Descriptor().FullName(), proto.Size(msg))
+	}
+
+	return fmt.Sprintf("<%T>", payload)
+}
+
+func (p *PayloadPolicy) truncate(s string) string {
+	if p.maxPayloadBytes <= 0 || len(s) <= p.maxPayloadBytes {
+		return s
+	}
+
+	// cut on a rune boundary so multi-byte text doesn't end in invalid UTF-8
+	cut := p.maxPayloadBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s...(truncated, %d bytes total)", s[:cut], len(s))
+}
+
+func (p *PayloadPolicy) redact(m protoreflect.Message, prefix string) {
+	var redacted []protoreflect.FieldDescriptor
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		path := string(fd.Name())
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		if slices.Contains(p.redactNames, string(fd.Name())) || slices.Contains(p.redactPaths, path) {
+			redacted = append(redacted, fd)
+			return true
+		}
+
+		switch {
+		case fd.IsList() && fd.Kind() == protoreflect.MessageKind:
+			list := v.List()
+			for i := range list.Len() {
+				p.redact(list.Get(i).Message(), path)
+			}
+		case fd.IsMap() && fd.MapValue().Kind() == protoreflect.MessageKind:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				p.redact(mv.Message(), path)
+				return true
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Kind() == protoreflect.MessageKind:
+			p.redact(v.Message(), path)
+		}
+
+		return true
+	})
+
+	for _, fd := range redacted {
+		if fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap() {
+			m.Set(fd, protoreflect.ValueOfString(redactedValue))
+			continue
+		}
+		m.Clear(fd)
+	}
+}